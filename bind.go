@@ -0,0 +1,81 @@
+package rv
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// handlerCache holds the RequestHandlers built by Bind and MustHandler,
+// keyed by struct type.
+var handlerCache sync.Map
+
+type cachedHandler struct {
+	handler *RequestHandler
+	err     error
+}
+
+// Bind fills a new T from the request using the RequestHandler for T,
+// which is built the first time T is bound and then shared by the whole
+// process. Problems with the request are returned as ValidationErrors,
+// see RequestHandler.Fill.
+//
+// The shared RequestHandler is built without Options, and only sees the
+// handlers registered with RegisterHandler before T is first bound, so
+// register them in an init function. Use BindWith to bind with Options.
+//
+//	args, err := rv.Bind[itemArgs](req)
+func Bind[T any](req Request) (T, error) {
+	handler, err := handlerFor[T]()
+	if err != nil {
+		var val T
+		return val, err
+	}
+	return BindWith[T](handler, req)
+}
+
+// BindWith is like Bind, but uses the given RequestHandler for T, such
+// as one built once with Options:
+//
+//	var itemHandler, _ = rv.NewRequestHandler(itemArgs{}, rv.WithFailFast(true))
+//
+//	args, err := rv.BindWith[itemArgs](itemHandler, req)
+func BindWith[T any](handler *RequestHandler, req Request) (T, error) {
+	var val T
+	err := handler.Fill(req, &val)
+	return val, err
+}
+
+// MustHandler returns the RequestHandler Bind uses for T, panicking if
+// T's tags are invalid. Like Bind, it builds the handler the first time
+// T is seen, with the handlers registered by then. Calling it when the
+// program starts, such as in a package var, finds bad tags before any
+// requests are served.
+//
+//	var _ = rv.MustHandler[itemArgs]()
+func MustHandler[T any]() *RequestHandler {
+	handler, err := handlerFor[T]()
+	if err != nil {
+		panic("Unable to create RequestHandler: " + err.Error())
+	}
+	return handler
+}
+
+// handlerFor returns the cached RequestHandler for T, building it if
+// this is the first time T has been seen.
+func handlerFor[T any]() (*RequestHandler, error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if cached, ok := handlerCache.Load(t); ok {
+		return cached.(cachedHandler).handler, cached.(cachedHandler).err
+	}
+
+	var handler *RequestHandler
+	var err error
+	if t.Kind() != reflect.Struct {
+		err = fmt.Errorf("Expected struct, got %s", t)
+	} else {
+		handler, err = NewRequestHandler(reflect.Zero(t).Interface())
+	}
+	cached, _ := handlerCache.LoadOrStore(t, cachedHandler{handler, err})
+	return cached.(cachedHandler).handler, cached.(cachedHandler).err
+}
@@ -0,0 +1,184 @@
+package rv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+)
+
+// DefaultMaxBodySize is the largest body, in bytes, a CachedRequest
+// will buffer when it isn't given its own limit.
+const DefaultMaxBodySize = 10 << 20
+
+// RawBodyRequest is implemented by Requests which can hand over the
+// unparsed request body. CachedRequest reads such a body once and
+// decodes it itself, so the same body can be read as JSON and as a
+// form.
+type RawBodyRequest interface {
+	Request
+	// RawBody returns the request body, which may only be read once
+	RawBody() (io.Reader, error)
+}
+
+// CachedRequest wraps a Request so that each source is only parsed
+// once, however many fields read from it. RequestHandler.Run wraps
+// the Request it is given in a CachedRequest.
+//
+// If the wrapped Request is a RawBodyRequest, a body which isn't
+// multipart is buffered, up to MaxBodySize bytes, and decoded from the
+// buffer. Multipart bodies are left to the wrapped Request so large
+// files aren't held in memory. Numbers in a buffered JSON body are
+// decoded as json.Number, so integers too big for a float64, like
+// 64-bit IDs, convert exactly.
+type CachedRequest struct {
+	Request
+	// MaxBodySize limits how much of the body is buffered,
+	// DefaultMaxBodySize is used if it is zero.
+	MaxBodySize int64
+
+	query     *cachedValue
+	path      *cachedValue
+	json      *cachedValue
+	form      *cachedValue
+	multipart *cachedValue
+	header    *cachedValue
+	cookies   *cachedValue
+	body      *cachedValue
+}
+
+type cachedValue struct {
+	val interface{}
+	err error
+}
+
+// NewCachedRequest wraps req in a CachedRequest, unless it already is
+// one.
+func NewCachedRequest(req Request, maxBodySize int64) *CachedRequest {
+	if cached, ok := req.(*CachedRequest); ok {
+		return cached
+	}
+	return &CachedRequest{Request: req, MaxBodySize: maxBodySize}
+}
+
+// cache returns the value in *c, calling load to fill it the first
+// time.
+func cache(c **cachedValue, load func() (interface{}, error)) (interface{}, error) {
+	if *c == nil {
+		val, err := load()
+		*c = &cachedValue{val: val, err: err}
+	}
+	return (*c).val, (*c).err
+}
+
+// QueryArgs returns the wrapped Request's query parameters
+func (r *CachedRequest) QueryArgs() (url.Values, error) {
+	val, err := cache(&r.query, func() (interface{}, error) { return r.Request.QueryArgs() })
+	vals, _ := val.(url.Values)
+	return vals, err
+}
+
+// PathArgs returns the wrapped Request's path parameters
+func (r *CachedRequest) PathArgs() (map[string]string, error) {
+	val, err := cache(&r.path, func() (interface{}, error) { return r.Request.PathArgs() })
+	args, _ := val.(map[string]string)
+	return args, err
+}
+
+// BodyJSON returns the body parsed as JSON
+func (r *CachedRequest) BodyJSON() (map[string]interface{}, error) {
+	val, err := cache(&r.json, func() (interface{}, error) {
+		body, ok, err := r.rawBody()
+		if !ok {
+			return r.Request.BodyJSON()
+		} else if err != nil {
+			return nil, err
+		}
+		return decodeJSONBody(body, true)
+	})
+	json, _ := val.(map[string]interface{})
+	return json, err
+}
+
+// BodyForm returns the body parsed as a form
+func (r *CachedRequest) BodyForm() (url.Values, error) {
+	val, err := cache(&r.form, func() (interface{}, error) {
+		body, ok, err := r.rawBody()
+		if !ok {
+			return r.Request.BodyForm()
+		} else if err != nil || body == nil {
+			return nil, err
+		}
+		data, err := ioutil.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		return url.ParseQuery(string(data))
+	})
+	vals, _ := val.(url.Values)
+	return vals, err
+}
+
+// BodyMultipart returns the body parsed as multipart/form-data, or an
+// error if the wrapped Request isn't a MultipartRequest
+func (r *CachedRequest) BodyMultipart() (*multipart.Form, error) {
+	val, err := cache(&r.multipart, func() (interface{}, error) { return requestMultipart(r.Request) })
+	form, _ := val.(*multipart.Form)
+	return form, err
+}
+
+// HeaderArgs returns the wrapped Request's headers, or an error if it
+// isn't a HeaderRequest
+func (r *CachedRequest) HeaderArgs() (http.Header, error) {
+	val, err := cache(&r.header, func() (interface{}, error) { return requestHeader(r.Request) })
+	header, _ := val.(http.Header)
+	return header, err
+}
+
+// CookieArgs returns the wrapped Request's cookies, or an error if it
+// isn't a CookieRequest
+func (r *CachedRequest) CookieArgs() ([]*http.Cookie, error) {
+	val, err := cache(&r.cookies, func() (interface{}, error) { return requestCookies(r.Request) })
+	cookies, _ := val.([]*http.Cookie)
+	return cookies, err
+}
+
+// rawBody returns a reader over the buffered body, or false if the
+// body should be parsed by the wrapped Request instead because it
+// doesn't provide the raw body or the body is multipart.
+func (r *CachedRequest) rawBody() (io.Reader, bool, error) {
+	raw, ok := r.Request.(RawBodyRequest)
+	if !ok {
+		return nil, false, nil
+	}
+	if header, err := r.HeaderArgs(); err == nil && IsMultipart(header.Get("Content-Type")) {
+		return nil, false, nil
+	}
+
+	val, err := cache(&r.body, func() (interface{}, error) {
+		body, err := raw.RawBody()
+		if err != nil || body == nil {
+			return nil, err
+		}
+
+		limit := r.MaxBodySize
+		if limit <= 0 {
+			limit = DefaultMaxBodySize
+		}
+		data, err := ioutil.ReadAll(io.LimitReader(body, limit+1))
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(data)) > limit {
+			return nil, fmt.Errorf("request body is larger than %d bytes", limit)
+		}
+		return data, nil
+	})
+	if err != nil || val == nil {
+		return nil, true, err
+	}
+	return bytes.NewReader(val.([]byte)), true, nil
+}
@@ -0,0 +1,102 @@
+package rv
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Converter converts a value pulled from a request, such as a string
+// from the query or a decoded JSON value, into a custom type.
+type Converter func(value interface{}) (interface{}, error)
+
+var (
+	converters     = map[reflect.Type]Converter{}
+	convertersLock sync.RWMutex
+
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// RegisterConverter makes rv use convert for fields of type t, as well
+// as pointers to, slices of and maps of t. A registered Converter takes
+// precedence over rv's own conversions. It affects RequestHandlers
+// created after it is called, so it should be called during startup.
+func RegisterConverter(t reflect.Type, convert Converter) {
+	convertersLock.Lock()
+	defer convertersLock.Unlock()
+	converters[t] = convert
+}
+
+func converterFor(t reflect.Type) (Converter, bool) {
+	convertersLock.RLock()
+	defer convertersLock.RUnlock()
+	convert, ok := converters[t]
+	return convert, ok
+}
+
+// isCustomType reports whether values of type t are converted by a
+// CustomTypeHandler: t has a registered Converter or unmarshals itself
+// via encoding.TextUnmarshaler or json.Unmarshaler.
+func isCustomType(t reflect.Type) bool {
+	if _, ok := converterFor(t); ok {
+		return true
+	}
+	if t == timeType || t == fileHeaderType {
+		return false
+	}
+	ptr := reflect.PtrTo(t)
+	return ptr.Implements(textUnmarshalerType) || ptr.Implements(jsonUnmarshalerType)
+}
+
+// CustomTypeHandler converts values to Type with its registered
+// Converter or, failing that, its UnmarshalText method for strings and
+// its UnmarshalJSON method for other values.
+type CustomTypeHandler struct {
+	Type reflect.Type
+}
+
+func (h CustomTypeHandler) Precidence() int { return ConvertStage }
+
+func (h CustomTypeHandler) Run(r Request, f *Field) {
+	if f.Value == nil || reflect.TypeOf(f.Value) == h.Type {
+		return
+	}
+
+	val, err := h.convert(f.Value)
+	if err != nil {
+		f.Errors = append(f.Errors, wrapValidationError("type", f.Value, []string{h.Type.String()}, err))
+		f.Halt = true
+	} else {
+		f.Value = val
+	}
+}
+
+func (h CustomTypeHandler) convert(value interface{}) (interface{}, error) {
+	if convert, ok := converterFor(h.Type); ok {
+		return convert(value)
+	}
+
+	ptr := reflect.New(h.Type)
+	str, isString := value.(string)
+
+	if u, ok := ptr.Interface().(encoding.TextUnmarshaler); ok && isString {
+		if err := u.UnmarshalText([]byte(str)); err != nil {
+			return nil, err
+		}
+	} else if u, ok := ptr.Interface().(json.Unmarshaler); ok {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		if err := u.UnmarshalJSON(data); err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, fmt.Errorf("don't know how to convert %T to %v", value, h.Type)
+	}
+
+	return ptr.Elem().Interface(), nil
+}
@@ -0,0 +1,106 @@
+package rv
+
+import (
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"strconv"
+	"strings"
+)
+
+var sizeSuffixes = []struct {
+	suffix string
+	mult   int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// MaxSizeHandler rejects uploaded files larger than Size bytes.
+type MaxSizeHandler struct {
+	Size int64
+}
+
+// NewMaxSizeHandler accepts a size in bytes, optionally followed by a
+// KB, MB or GB suffix (e.g. "maxsize=2MB").
+func NewMaxSizeHandler(args []string) (FieldHandler, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("need a single argument for maxsize, got %#v", args)
+	}
+
+	num, mult := strings.ToUpper(args[0]), int64(1)
+	for _, s := range sizeSuffixes {
+		if strings.HasSuffix(num, s.suffix) {
+			num, mult = strings.TrimSpace(strings.TrimSuffix(num, s.suffix)), s.mult
+			break
+		}
+	}
+
+	size, err := strconv.ParseInt(num, 10, 64)
+	if err != nil || size < 0 {
+		return nil, fmt.Errorf("invalid maxsize %#v", args[0])
+	}
+	return MaxSizeHandler{Size: size * mult}, nil
+}
+
+func (h MaxSizeHandler) Run(req Request, field *Field) {
+	switch v := field.Value.(type) {
+	case nil:
+		// nothing uploaded, leave it to RequiredHandler
+	case *multipart.FileHeader:
+		if v.Size > h.Size {
+			field.Errors = append(field.Errors, newValidationError("maxsize", v, []string{strconv.FormatInt(h.Size, 10)},
+				"file %#v is %d bytes, larger than %d", v.Filename, v.Size, h.Size))
+		}
+	default:
+		field.Errors = append(field.Errors, newValidationError("maxsize", v, []string{strconv.FormatInt(h.Size, 10)},
+			"don't know how to determine size for %T", v))
+	}
+}
+
+// ContentTypesHandler rejects uploaded files whose Content-Type is not
+// one of Types. A type may end in "/*" to accept any subtype.
+type ContentTypesHandler struct {
+	Types []string
+}
+
+func NewContentTypesHandler(args []string) (FieldHandler, error) {
+	types := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "" {
+			return nil, fmt.Errorf("empty content type in %#v", args)
+		}
+		types = append(types, strings.ToLower(arg))
+	}
+	return ContentTypesHandler{Types: types}, nil
+}
+
+func (h ContentTypesHandler) Run(req Request, field *Field) {
+	switch v := field.Value.(type) {
+	case nil:
+		// nothing uploaded, leave it to RequiredHandler
+	case *multipart.FileHeader:
+		contentType, _, err := mime.ParseMediaType(v.Header.Get("Content-Type"))
+		if err != nil || !h.allowed(contentType) {
+			field.Errors = append(field.Errors, newValidationError("contenttypes", v, h.Types,
+				"Expected file of type %#v, got %#v", h.Types, v.Header.Get("Content-Type")))
+		}
+	default:
+		field.Errors = append(field.Errors, newValidationError("contenttypes", v, h.Types,
+			"don't know how to determine content type for %T", v))
+	}
+}
+
+func (h ContentTypesHandler) allowed(contentType string) bool {
+	for _, t := range h.Types {
+		if t == contentType {
+			return true
+		}
+		if strings.HasSuffix(t, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(t, "*")) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,98 +1,144 @@
 package gocraft
 
 import (
-	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/url"
+	"sort"
 
 	"github.com/OwnLocal/rv"
 	"github.com/gocraft/web"
 )
 
+// Request holds a gocraft web.Request, taking the path params gocraft
+// found from it. The values are read by an rv.HTTPRequest, so it behaves
+// like the adapters for every other router.
 type Request struct {
-	Request  *web.Request
-	bodyRead bool
+	Request *web.Request
+	// MaxMemory limits how much of a multipart body is kept in memory,
+	// rv.DefaultMaxMemory is used if it is zero.
+	MaxMemory int64
+
+	httpRequest *rv.HTTPRequest
 }
 
-func (r *Request) QueryArgs() (url.Values, error) {
-	vals, err := url.ParseQuery(r.Request.URL.RawQuery)
-	if err != nil {
-		return nil, err
+// reader returns the rv.HTTPRequest reading the request, made the first
+// time it's needed so a Request can be a struct literal.
+func (r *Request) reader() *rv.HTTPRequest {
+	if r.httpRequest == nil {
+		webRequest := r.Request
+		r.httpRequest = rv.NewHTTPRequest(webRequest.Request, func(*http.Request) map[string]string {
+			return webRequest.PathParams
+		})
 	}
-	return vals, nil
+	r.httpRequest.MaxMemory = r.MaxMemory
+	return r.httpRequest
 }
 
-func (r *Request) PathArgs() (map[string]string, error) {
-	return r.Request.PathParams, nil
-}
+func (r *Request) QueryArgs() (url.Values, error) { return r.reader().QueryArgs() }
 
-func (r *Request) BodyJSON() (map[string]interface{}, error) {
-	if r.bodyRead {
-		return nil, errors.New("body already read")
-	}
+func (r *Request) PathArgs() (map[string]string, error) { return r.reader().PathArgs() }
 
-	r.bodyRead = true
-	return rv.ParseJSONBody(r.Request.Body)
-}
+func (r *Request) BodyJSON() (map[string]interface{}, error) { return r.reader().BodyJSON() }
 
-func (r *Request) BodyForm() (url.Values, error) {
-	if r.bodyRead {
-		return nil, errors.New("body already read")
-	}
+func (r *Request) BodyForm() (url.Values, error) { return r.reader().BodyForm() }
 
-	r.bodyRead = true
+func (r *Request) BodyMultipart() (*multipart.Form, error) { return r.reader().BodyMultipart() }
 
-	if r.Request.Body == nil {
-		return nil, nil
-	}
+func (r *Request) HeaderArgs() (http.Header, error) { return r.reader().HeaderArgs() }
 
-	body, err := ioutil.ReadAll(r.Request.Body)
-	if err != nil {
-		return nil, err
-	}
+func (r *Request) CookieArgs() ([]*http.Cookie, error) { return r.reader().CookieArgs() }
 
-	return url.ParseQuery(string(body))
-}
+func (r *Request) RawBody() (io.Reader, error) { return r.reader().RawBody() }
 
-// Ensure *gocract.Request meets the rv.Request interface
-var _ rv.Request = (*Request)(nil)
+// Ensure *gocract.Request meets the rv.RawBodyRequest, rv.HeaderRequest,
+// rv.CookieRequest and rv.MultipartRequest interfaces
+var (
+	_ rv.RawBodyRequest   = (*Request)(nil)
+	_ rv.HeaderRequest    = (*Request)(nil)
+	_ rv.CookieRequest    = (*Request)(nil)
+	_ rv.MultipartRequest = (*Request)(nil)
+)
 
 // BindMiddleware creates an rv.RequestHandler for the specified field
 // type and returns a middleware which finds a field of that type on
 // the context and binds the values to that field via the
-// RequestHandler.
+// RequestHandler. Without an errorWriter, the errors are written by
+// rv.ErrorWriter in declaration order.
 func BindMiddleware(field interface{}, errorWriter ...func(web.ResponseWriter, error, map[string]rv.Field)) func(
 	interface{}, web.ResponseWriter, *web.Request, web.NextMiddlewareFunc) {
 
-	if len(errorWriter) < 1 {
-		errorWriter = append(errorWriter, ErrorWriter)
-	}
-
 	argHandler, err := rv.NewRequestHandler(field)
 	if err != nil {
 		panic("Unable to create RequestHandler: " + err.Error())
 	}
 
 	return func(ctx interface{}, rw web.ResponseWriter, r *web.Request, next web.NextMiddlewareFunc) {
-		err, fieldErrors := argHandler.Bind(&Request{Request: r}, ctx)
+		err, fieldErrors := argHandler.BindOrdered(&Request{Request: r}, ctx)
+		if err != nil || len(fieldErrors) > 0 {
+			writeErrors(rw, err, fieldErrors, errorWriter)
+		} else {
+			next(rw, r)
+		}
+	}
+}
+
+// BindAllMiddleware creates an rv.MultiHandler for the context type and
+// returns a middleware which fills every struct field of the context
+// whose type has rv tags, such as separate path, query and body
+// structs, writing the errors for all of them at once.
+func BindAllMiddleware(ctx interface{}, errorWriter ...func(web.ResponseWriter, error, map[string]rv.Field)) func(
+	interface{}, web.ResponseWriter, *web.Request, web.NextMiddlewareFunc) {
+
+	multiHandler, err := rv.NewMultiHandler(ctx)
+	if err != nil {
+		panic("Unable to create MultiHandler: " + err.Error())
+	}
+
+	return func(ctx interface{}, rw web.ResponseWriter, r *web.Request, next web.NextMiddlewareFunc) {
+		err, fieldErrors := multiHandler.RunOrdered(&Request{Request: r}, ctx)
 		if err != nil || len(fieldErrors) > 0 {
-			errorWriter[0](rw, err, fieldErrors)
+			writeErrors(rw, err, fieldErrors, errorWriter)
 		} else {
 			next(rw, r)
 		}
 	}
 }
 
+// writeErrors passes the errors to errorWriter if one was given, or
+// writes them in declaration order with rv.ErrorWriter.
+func writeErrors(rw web.ResponseWriter, argErr error, fieldErrors rv.FieldErrors, errorWriter []func(web.ResponseWriter, error, map[string]rv.Field)) {
+	if len(errorWriter) > 0 {
+		var fields map[string]rv.Field
+		if argErr == nil {
+			fields = fieldErrors.Map()
+		}
+		errorWriter[0](rw, argErr, fields)
+		return
+	}
+	rv.ErrorWriter(rw, argErr, fieldErrors)
+}
+
+// ErrorWriter panics on argument errors and writes the field errors
+// with a 400 status, ordered by name as they're given in a map. The
+// middlewares write them in declaration order when no errorWriter is
+// given.
 func ErrorWriter(rw web.ResponseWriter, argErr error, fieldErrors map[string]rv.Field) {
 	if argErr != nil {
 		panic(argErr)
 	}
 
+	names := make([]string, 0, len(fieldErrors))
+	for name := range fieldErrors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
 	rw.WriteHeader(http.StatusBadRequest)
-	for name, field := range fieldErrors {
-		for _, err := range field.Errors {
+	for _, name := range names {
+		for _, err := range fieldErrors[name].Errors {
 			fmt.Fprintln(rw, name, err)
 		}
 	}
@@ -1,11 +1,15 @@
 package gocraft_test
 
 import (
+	"bufio"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"strings"
 
+	"github.com/OwnLocal/rv"
 	. "github.com/OwnLocal/rv/gocraft"
 	"github.com/gocraft/web"
 
@@ -124,3 +128,117 @@ var _ = Describe("Request", func() {
 	})
 
 })
+
+var _ = Describe("BindMiddleware", func() {
+	type itemArgs struct {
+		ID    int    `rv:"path.id"`
+		Color string `rv:"query.color options=red,blue"`
+	}
+	type itemContext struct {
+		Args itemArgs
+	}
+
+	var res *recorder
+	var called bool
+	next := func(web.ResponseWriter, *web.Request) { called = true }
+
+	newRequest := func(target string, pathParams map[string]string) *web.Request {
+		return &web.Request{Request: httptest.NewRequest("GET", target, nil), PathParams: pathParams}
+	}
+
+	BeforeEach(func() {
+		res = &recorder{httptest.NewRecorder()}
+		called = false
+	})
+
+	It("binds the field of the context", func() {
+		ctx := itemContext{}
+		BindMiddleware(itemArgs{})(&ctx, res, newRequest("/items/42?color=red", map[string]string{"id": "42"}), next)
+		Expect(called).To(BeTrue())
+		Expect(ctx.Args).To(Equal(itemArgs{ID: 42, Color: "red"}))
+	})
+
+	It("writes the field errors in declaration order instead of calling next", func() {
+		BindMiddleware(itemArgs{})(&itemContext{}, res, newRequest("/items/x?color=green", map[string]string{"id": "x"}), next)
+		Expect(called).To(BeFalse())
+		Expect(res.Code).To(Equal(http.StatusBadRequest))
+		Expect(res.Body.String()).To(Equal(
+			"ID strconv.ParseInt: parsing \"x\": invalid syntax\n" +
+				"Color Expected one of []string{\"blue\", \"red\"}, got \"green\"\n"))
+	})
+
+	It("passes the field errors to the given error writer", func() {
+		var fieldErrs map[string]rv.Field
+		BindMiddleware(itemArgs{}, func(rw web.ResponseWriter, err error, errs map[string]rv.Field) {
+			fieldErrs = errs
+		})(&itemContext{}, res, newRequest("/items/x?color=red", map[string]string{"id": "x"}), next)
+		Expect(called).To(BeFalse())
+		Expect(fieldErrs).To(HaveLen(1))
+		Expect(fieldErrs).To(HaveKey("ID"))
+	})
+})
+
+var _ = Describe("BindAllMiddleware", func() {
+	type pathParams struct {
+		ID int `rv:"path.id"`
+	}
+	type queryParams struct {
+		Page int `rv:"query.page default=1 range=1,100"`
+	}
+	type itemContext struct {
+		Path  pathParams
+		Query *queryParams
+	}
+
+	var res *recorder
+	var called bool
+	next := func(web.ResponseWriter, *web.Request) { called = true }
+
+	newRequest := func(target string, pathParams map[string]string) *web.Request {
+		return &web.Request{Request: httptest.NewRequest("GET", target, nil), PathParams: pathParams}
+	}
+
+	BeforeEach(func() {
+		res = &recorder{httptest.NewRecorder()}
+		called = false
+	})
+
+	It("binds every struct field of the context", func() {
+		ctx := itemContext{}
+		BindAllMiddleware(itemContext{})(&ctx, res, newRequest("/items/42?page=3", map[string]string{"id": "42"}), next)
+		Expect(called).To(BeTrue())
+		Expect(ctx).To(Equal(itemContext{Path: pathParams{ID: 42}, Query: &queryParams{Page: 3}}))
+	})
+
+	It("writes the errors of every struct at once, in declaration order", func() {
+		BindAllMiddleware(itemContext{})(&itemContext{}, res, newRequest("/items/x?page=0", map[string]string{"id": "x"}), next)
+		Expect(called).To(BeFalse())
+		Expect(res.Code).To(Equal(http.StatusBadRequest))
+		Expect(res.Body.String()).To(Equal(
+			"Path.ID strconv.ParseInt: parsing \"x\": invalid syntax\n" +
+				"Query.Page 0 not in range 1, 100\n"))
+	})
+
+	It("passes the field errors to the given error writer", func() {
+		var fieldErrs map[string]rv.Field
+		BindAllMiddleware(itemContext{}, func(rw web.ResponseWriter, err error, errs map[string]rv.Field) {
+			fieldErrs = errs
+		})(&itemContext{}, res, newRequest("/items/x", map[string]string{"id": "x"}), next)
+		Expect(called).To(BeFalse())
+		Expect(fieldErrs).To(HaveLen(1))
+		Expect(fieldErrs).To(HaveKey("Path.ID"))
+	})
+})
+
+// recorder is an httptest.ResponseRecorder which is a web.ResponseWriter
+type recorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (r *recorder) StatusCode() int          { return r.Code }
+func (r *recorder) Written() bool            { return r.Body.Len() > 0 }
+func (r *recorder) Size() int                { return r.Body.Len() }
+func (r *recorder) CloseNotify() <-chan bool { return make(chan bool) }
+func (r *recorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, http.ErrNotSupported
+}
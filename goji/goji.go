@@ -1,8 +1,8 @@
 package goji
 
 import (
-	"errors"
-	"io/ioutil"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 
@@ -11,61 +11,92 @@ import (
 )
 
 // Request holds a http.Request and knows how to pull Goji path params from the request context.
+// The values are read by an rv.HTTPRequest, so it behaves like the
+// adapters for every other router.
 type Request struct {
 	*http.Request
-	bodyRead bool
+	// MaxMemory limits how much of a multipart body is kept in memory,
+	// rv.DefaultMaxMemory is used if it is zero.
+	MaxMemory int64
+
+	httpRequest *rv.HTTPRequest
 }
 
-// QueryArgs pulls the standard query args from the request.
-func (r *Request) QueryArgs() (url.Values, error) {
-	vals, err := url.ParseQuery(r.Request.URL.RawQuery) // Doesn't use URL.Query because we want to see errors.
-	if err != nil {
-		return nil, err
+// reader returns the rv.HTTPRequest reading the request, made the first
+// time it's needed so a Request can be a struct literal.
+func (r *Request) reader() *rv.HTTPRequest {
+	if r.httpRequest == nil {
+		r.httpRequest = rv.NewHTTPRequest(r.Request, PathVariables)
 	}
-	return vals, nil
+	r.httpRequest.MaxMemory = r.MaxMemory
+	return r.httpRequest
 }
 
-// PathArgs extracts all Goji path variables from the request context.
-func (r *Request) PathArgs() (map[string]string, error) {
+// PathVariables is an rv.PathExtractor returning all the Goji path
+// variables from the request context.
+func PathVariables(r *http.Request) map[string]string {
 	if pathVars, ok := r.Context().Value(pattern.AllVariables).(map[pattern.Variable]interface{}); ok {
 		pathMap := make(map[string]string, len(pathVars))
 		for k, v := range pathVars {
 			pathMap[string(k)] = v.(string)
 		}
-		return pathMap, nil
+		return pathMap
 	}
-	return nil, nil
+	return nil
 }
 
+// QueryArgs pulls the standard query args from the request.
+func (r *Request) QueryArgs() (url.Values, error) { return r.reader().QueryArgs() }
+
+// PathArgs extracts all Goji path variables from the request context.
+func (r *Request) PathArgs() (map[string]string, error) { return r.reader().PathArgs() }
+
 // BodyJSON parses and returns a map of values from the body.
-func (r *Request) BodyJSON() (map[string]interface{}, error) {
-	if r.bodyRead {
-		return nil, errors.New("body already read")
-	}
+func (r *Request) BodyJSON() (map[string]interface{}, error) { return r.reader().BodyJSON() }
 
-	r.bodyRead = true
-	return rv.ParseJSONBody(r.Request.Body)
-}
+// BodyForm parses and returns any values from a body form, using the
+// text parts of a multipart body.
+func (r *Request) BodyForm() (url.Values, error) { return r.reader().BodyForm() }
 
-// BodyForm parses and returns any values from a body form.
-func (r *Request) BodyForm() (url.Values, error) {
-	if r.bodyRead {
-		return nil, errors.New("body already read")
-	}
+// BodyMultipart parses and returns a multipart/form-data body, see
+// rv.HTTPRequest.BodyMultipart.
+func (r *Request) BodyMultipart() (*multipart.Form, error) { return r.reader().BodyMultipart() }
 
-	r.bodyRead = true
+// HeaderArgs returns the request headers.
+func (r *Request) HeaderArgs() (http.Header, error) { return r.reader().HeaderArgs() }
 
-	if r.Request.Body == nil {
-		return nil, nil
-	}
+// CookieArgs returns the cookies sent with the request.
+func (r *Request) CookieArgs() ([]*http.Cookie, error) { return r.reader().CookieArgs() }
 
-	body, err := ioutil.ReadAll(r.Request.Body)
-	if err != nil {
-		return nil, err
-	}
+// RawBody returns the request body so rv.CachedRequest can buffer it
+// and parse it as both JSON and a form.
+func (r *Request) RawBody() (io.Reader, error) { return r.reader().RawBody() }
+
+// Ensure *goji.Request meets the rv.RawBodyRequest, rv.HeaderRequest,
+// rv.CookieRequest and rv.MultipartRequest interfaces
+var (
+	_ rv.RawBodyRequest   = (*Request)(nil)
+	_ rv.HeaderRequest    = (*Request)(nil)
+	_ rv.CookieRequest    = (*Request)(nil)
+	_ rv.MultipartRequest = (*Request)(nil)
+)
+
+// newRequest is the newRequest function for rv.BindMiddleware.
+func newRequest(r *http.Request) rv.Request {
+	return &Request{Request: r}
+}
 
-	return url.ParseQuery(string(body))
+// BindMiddleware is rv.BindMiddleware for Goji, storing the bound value
+// for rv.Bound. It wraps the route's own handler so the path variables
+// have been matched:
+//
+//	mux.Handle(pat.Get("/items/:id"), goji.BindMiddleware(itemArgs{})(itemHandler))
+func BindMiddleware(field interface{}, errorWriter ...func(http.ResponseWriter, error, rv.FieldErrors)) func(http.Handler) http.Handler {
+	return rv.BindMiddleware(field, newRequest, errorWriter...)
 }
 
-// Ensure *gocract.Request meets the rv.Request interface
-var _ rv.Request = (*Request)(nil)
+// BindAllMiddleware is rv.BindAllMiddleware for Goji, storing the filled
+// container for rv.Bound.
+func BindAllMiddleware(container interface{}, errorWriter ...func(http.ResponseWriter, error, rv.FieldErrors)) func(http.Handler) http.Handler {
+	return rv.BindAllMiddleware(container, newRequest, errorWriter...)
+}
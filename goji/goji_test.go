@@ -12,6 +12,7 @@ import (
 
 	goji "goji.io"
 
+	"github.com/OwnLocal/rv"
 	. "github.com/OwnLocal/rv/goji"
 
 	. "github.com/onsi/ginkgo"
@@ -154,3 +155,112 @@ var _ = Describe("Request", func() {
 	})
 
 })
+
+var _ = Describe("BindMiddleware", func() {
+	type itemArgs struct {
+		ID    int    `rv:"path.id"`
+		Color string `rv:"query.color options=red,blue"`
+	}
+	type pageArgs struct {
+		Page int `rv:"query.page default=1"`
+	}
+
+	var mux *goji.Mux
+	var res *httptest.ResponseRecorder
+	var bound itemArgs
+	var page pageArgs
+	var ok bool
+
+	BeforeEach(func() {
+		bound, page, ok = itemArgs{}, pageArgs{}, false
+		res = httptest.NewRecorder()
+
+		mux = goji.NewMux()
+		mux.Handle(pat.Get("/items/:id"), BindMiddleware(itemArgs{})(BindMiddleware(pageArgs{})(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				bound, ok = rv.Bound[itemArgs](r)
+				page, _ = rv.Bound[pageArgs](r)
+			}))))
+	})
+
+	It("binds the struct and passes it on in the request context", func() {
+		mux.ServeHTTP(res, httptest.NewRequest("GET", "/items/42?color=red&page=3", nil))
+		Expect(res.Code).To(Equal(http.StatusOK))
+		Expect(ok).To(BeTrue())
+		Expect(bound).To(Equal(itemArgs{ID: 42, Color: "red"}))
+		Expect(page).To(Equal(pageArgs{Page: 3}))
+	})
+
+	It("writes the field errors in declaration order instead of calling the handler", func() {
+		mux.ServeHTTP(res, httptest.NewRequest("GET", "/items/x?color=green", nil))
+		Expect(res.Code).To(Equal(http.StatusBadRequest))
+		Expect(ok).To(BeFalse())
+		Expect(res.Body.String()).To(Equal(
+			"ID strconv.ParseInt: parsing \"x\": invalid syntax\n" +
+				"Color Expected one of []string{\"blue\", \"red\"}, got \"green\"\n"))
+	})
+
+	It("uses the given error writer", func() {
+		var fieldErrs rv.FieldErrors
+		handler := BindMiddleware(itemArgs{}, func(w http.ResponseWriter, err error, errs rv.FieldErrors) {
+			fieldErrs = errs
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		})(http.NotFoundHandler())
+		mux.Handle(pat.Get("/other/:id"), handler)
+
+		mux.ServeHTTP(res, httptest.NewRequest("GET", "/other/x", nil))
+		Expect(res.Code).To(Equal(http.StatusUnprocessableEntity))
+		Expect(fieldErrs).NotTo(BeEmpty())
+		Expect(fieldErrs[0].Name).To(Equal("ID"))
+	})
+
+	It("returns nothing for structs which weren't bound", func() {
+		_, ok := rv.Bound[itemArgs](httptest.NewRequest("GET", "/", nil))
+		Expect(ok).To(BeFalse())
+	})
+})
+
+var _ = Describe("BindAllMiddleware", func() {
+	type pathParams struct {
+		ID int `rv:"path.id"`
+	}
+	type queryParams struct {
+		Page int `rv:"query.page default=1 range=1,100"`
+	}
+	type itemContext struct {
+		Path  pathParams
+		Query queryParams
+	}
+
+	var mux *goji.Mux
+	var res *httptest.ResponseRecorder
+	var bound itemContext
+	var ok bool
+
+	BeforeEach(func() {
+		bound, ok = itemContext{}, false
+		res = httptest.NewRecorder()
+
+		mux = goji.NewMux()
+		mux.Handle(pat.Get("/items/:id"), BindAllMiddleware(itemContext{})(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				bound, ok = rv.Bound[itemContext](r)
+			})))
+	})
+
+	It("binds every struct and passes the container on in the request context", func() {
+		mux.ServeHTTP(res, httptest.NewRequest("GET", "/items/42?page=3", nil))
+		Expect(res.Code).To(Equal(http.StatusOK))
+		Expect(ok).To(BeTrue())
+		Expect(bound).To(Equal(itemContext{Path: pathParams{ID: 42}, Query: queryParams{Page: 3}}))
+	})
+
+	It("writes the errors of every struct at once", func() {
+		mux.ServeHTTP(res, httptest.NewRequest("GET", "/items/x?page=0", nil))
+		Expect(res.Code).To(Equal(http.StatusBadRequest))
+		Expect(ok).To(BeFalse())
+		Expect(res.Body.String()).To(Equal(
+			"Path.ID strconv.ParseInt: parsing \"x\": invalid syntax\n" +
+				"Query.Page 0 not in range 1, 100\n"))
+	})
+})
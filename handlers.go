@@ -3,6 +3,7 @@ package rv
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -13,14 +14,51 @@ type FieldHandler interface {
 	Run(Request, *Field)
 }
 
+// PrecidenceFieldHandler is a FieldHandler which controls when it runs
+// relative to the other handlers on a field: handlers with a higher
+// Precidence run first. The handlers on a field run in stages, and the
+// built-in handlers use
+//
+//	SourceStage    1000  source
+//	                950  strict JSON types
+//	DefaultStage    900  default
+//	                899  halting absent pointer fields
+//	ConvertStage    800  type conversion
+//	ValidateStage     0  range, options, maxsize, contenttypes
+//	RequiredStage  -100  required
+//
+// A handler which doesn't implement PrecidenceFieldHandler runs in
+// ValidateStage, after the value has been converted to the field's
+// type. On slice and map fields, all handlers other than source, strict
+// JSON types and default run on each element rather than on the whole
+// value.
 type PrecidenceFieldHandler interface {
 	FieldHandler
 	Precidence() int
 }
 
+// The stages of the handlers on a field, as Precidence values.
+const (
+	SourceStage   = 1000
+	DefaultStage  = 900
+	ConvertStage  = 800
+	ValidateStage = 0
+	RequiredStage = -100
+)
+
 type Field struct {
 	Value  interface{}
 	Errors []error
+	// Null is set when the source explicitly provided a null value,
+	// which is treated as no value but resets pointer fields to nil.
+	Null bool
+	// Fields holds the results of any nested fields which had errors,
+	// keyed by their path relative to this field.
+	Fields map[string]Field
+	// Halt is set by a handler to stop the remaining handlers on the
+	// field from running, such as when the value couldn't be converted
+	// and validating it would only give confusing errors.
+	Halt bool
 }
 
 type DefaultHandler struct {
@@ -41,7 +79,33 @@ func (h DefaultHandler) Run(req Request, field *Field) {
 }
 
 // goes before TypeHandler so the default string will be transformed into the right type
-func (h DefaultHandler) Precidence() int { return 900 }
+func (h DefaultHandler) Precidence() int { return DefaultStage }
+
+// absentHandler halts a pointer field which still has no value after
+// the default stage, so the validators don't run on it and only its
+// required handlers can complain.
+type absentHandler struct {
+	Required FieldHandlers
+}
+
+func newAbsentHandler(fieldHandlers FieldHandlers) absentHandler {
+	var h absentHandler
+	for _, handler := range fieldHandlers {
+		if required, ok := handler.(RequiredHandler); ok {
+			h.Required = append(h.Required, required)
+		}
+	}
+	return h
+}
+
+func (h absentHandler) Run(req Request, field *Field) {
+	if field.Value == nil {
+		h.Required.Run(req, field)
+		field.Halt = true
+	}
+}
+
+func (h absentHandler) Precidence() int { return DefaultStage - 1 }
 
 type RangeHandler struct {
 	Start string
@@ -57,41 +121,42 @@ func NewRangeHandler(args []string) (FieldHandler, error) {
 
 func (h RangeHandler) Run(req Request, field *Field) {
 	var err error
+	params := []string{h.Start, h.End}
 
 	switch v := field.Value.(type) {
 	case nil:
-		err = fmt.Errorf("need value in range %s, %s, got no value", h.Start, h.End)
+		err = newValidationError("range", nil, params, "need value in range %s, %s, got no value", h.Start, h.End)
 	case int, int8, int16, int32, int64:
 		var min, max int64
 		i := reflect.ValueOf(v).Int()
 		min, max, err = h.intRange()
 		if err == nil && i < min || i > max {
-			err = fmt.Errorf("%d not in range %d, %d", i, min, max)
+			err = newValidationError("range", v, params, "%d not in range %d, %d", i, min, max)
 		}
 	case uint, uint8, uint16, uint32, uint64:
 		var min, max uint64
 		i := reflect.ValueOf(v).Uint()
 		min, max, err = h.uintRange()
 		if err == nil && i < min || i > max {
-			err = fmt.Errorf("%d not in range %d, %d", i, min, max)
+			err = newValidationError("range", v, params, "%d not in range %d, %d", i, min, max)
 		}
 	case float32, float64:
 		var min, max float64
 		f := reflect.ValueOf(v).Float()
 		min, max, err = h.floatRange()
 		if err == nil && f < min || f > max {
-			err = fmt.Errorf("%f not in range %f, %f", f, min, max)
+			err = newValidationError("range", v, params, "%f not in range %f, %f", f, min, max)
 		}
 	case string:
 		if v < h.Start || v > h.End {
-			err = fmt.Errorf("%#v not in range %#v, %#v", v, h.Start, h.End)
+			err = newValidationError("range", v, params, "%#v not in range %#v, %#v", v, h.Start, h.End)
 		}
 	default:
-		err = fmt.Errorf("don't know how to determine range for %T(%v)", v, v)
+		err = newValidationError("range", v, params, "don't know how to determine range for %T(%v)", v, v)
 	}
 
 	if err != nil {
-		field.Errors = append(field.Errors, err)
+		field.Errors = append(field.Errors, wrapValidationError("range", field.Value, params, err))
 	}
 }
 
@@ -138,12 +203,59 @@ func (h OptionsHandler) Run(req Request, field *Field) {
 		for opt, _ := range h.Options {
 			options = append(options, opt)
 		}
-		field.Errors = append(field.Errors, fmt.Errorf("Expected one of %#v, got %#v", options, val))
+		sort.Strings(options)
+		field.Errors = append(field.Errors, newValidationError("options", field.Value, options, "Expected one of %#v, got %#v", options, val))
 	}
 }
 
+// ListHandler runs SubHandlers on each element of a slice field. A
+// string value, or each of the strings in a list of repeated query,
+// form or header values, is split on Separator (a comma if it is
+// empty) unless NoSplit is set. JSON arrays are used as they are.
+// TrimSpace trims the spaces around each element, as HTTP list headers
+// like "Accept: text/html, application/json" allow.
 type ListHandler struct {
 	SubHandlers FieldHandlers
+	Separator   string
+	NoSplit     bool
+	TrimSpace   bool
+}
+
+var separatorNames = map[string]string{
+	"comma": ",",
+	"space": " ",
+	"tab":   "\t",
+	"none":  "",
+}
+
+// newListSeparator parses the "sep" tag option, which takes either a
+// literal separator or one of the names in separatorNames for those
+// which can't be written in a tag.
+func newListSeparator(args []string) (sep string, noSplit bool, err error) {
+	if len(args) != 1 || args[0] == "" {
+		return "", false, fmt.Errorf("need a single argument for sep, got %#v", args)
+	}
+	if named, ok := separatorNames[args[0]]; ok {
+		return named, named == "", nil
+	}
+	return args[0], false, nil
+}
+
+func (h ListHandler) split(val string) []string {
+	if h.NoSplit {
+		return []string{val}
+	}
+	sep := h.Separator
+	if sep == "" {
+		sep = ","
+	}
+	parts := strings.Split(val, sep)
+	if h.TrimSpace {
+		for i, part := range parts {
+			parts[i] = strings.TrimSpace(part)
+		}
+	}
+	return parts
 }
 
 func (h ListHandler) Run(req Request, field *Field) {
@@ -151,9 +263,15 @@ func (h ListHandler) Run(req Request, field *Field) {
 	if field.Value == nil {
 		return
 	} else if val, ok := field.Value.(string); ok {
-		for _, part := range strings.Split(val, ",") {
+		for _, part := range h.split(val) {
 			fields = append(fields, &Field{Value: part})
 		}
+	} else if vals, ok := field.Value.([]string); ok {
+		for _, val := range vals {
+			for _, part := range h.split(val) {
+				fields = append(fields, &Field{Value: part})
+			}
+		}
 	} else if reflect.TypeOf(field.Value).Kind() == reflect.Slice {
 		slice := reflect.ValueOf(field.Value)
 		for i := 0; i < slice.Len(); i++ {
@@ -164,19 +282,33 @@ func (h ListHandler) Run(req Request, field *Field) {
 	}
 
 	for _, subField := range fields {
-		for _, handler := range h.SubHandlers {
-			handler.Run(req, subField)
-		}
+		h.SubHandlers.Run(req, subField)
 	}
 
 	if len(fields) == 0 {
 		return
 	}
 
-	valSlice := reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(fields[0].Value)), 0, len(fields))
+	elemType := reflect.TypeOf(fields[0].Value)
+	for i, subField := range fields {
+		field.Errors = append(field.Errors, subField.Errors...)
+		for path, nested := range subField.Fields {
+			nestSources(nested.Errors, []string{strconv.Itoa(i)})
+			addNestedField(field, joinPath(fmt.Sprintf("[%d]", i), path), nested)
+		}
+		if reflect.TypeOf(subField.Value) != elemType {
+			elemType = nil
+		}
+	}
+
+	// Leave the value alone if the items didn't all end up the same type
+	if elemType == nil {
+		return
+	}
+
+	valSlice := reflect.MakeSlice(reflect.SliceOf(elemType), 0, len(fields))
 	for _, subField := range fields {
 		valSlice = reflect.Append(valSlice, reflect.ValueOf(subField.Value))
-		field.Errors = append(field.Errors, subField.Errors...)
 	}
 	field.Value = valSlice.Interface()
 }
@@ -196,10 +328,10 @@ func NewRequiredHandler(args []string) (FieldHandler, error) {
 
 func (h RequiredHandler) Run(req Request, field *Field) {
 	if h.Required && field.Value == nil {
-		field.Errors = append(field.Errors, fmt.Errorf("required field missing"))
+		field.Errors = append(field.Errors, newValidationError("required", nil, nil, "required field missing"))
 	}
 }
 
 func (h RequiredHandler) Precidence() int {
-	return -100
+	return RequiredStage
 }
@@ -1,8 +1,12 @@
 package rv_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
 	"strings"
 	"time"
 
@@ -32,7 +36,12 @@ var _ = Describe("Validators", func() {
 	BeforeEach(func() {
 		req = &rv.BasicRequest{
 			Path:  map[string]string{"foo": "bar"},
-			Query: "bar=baz&blah=flah"}
+			Query: "bar=baz&blah=flah",
+			Header: http.Header{
+				"X-Request-Id": []string{"abc123"},
+				"Accept":       []string{"text/html", "application/json"},
+				"Cookie":       []string{"session=s3cr3t"},
+			}}
 		field = new(rv.Field)
 	})
 
@@ -43,11 +52,31 @@ var _ = Describe("Validators", func() {
 				Expect(err).To(HaveOccurred())
 			})
 
+			It("rejects sources without a field name", func() {
+				_, err := rv.NewSourceFieldHandler([]string{"json"})
+				Expect(err).To(HaveOccurred())
+				_, err = rv.NewSourceFieldHandler([]string{"json."})
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("accepts paths into JSON bodies", func() {
+				Expect(rv.NewSourceFieldHandler([]string{"json.user.email"})).To(Equal(rv.SourceFieldHandler{Source: rv.JSON, Field: "user.email"}))
+				Expect(rv.NewSourceFieldHandler([]string{"json./items/0/id"})).To(Equal(rv.SourceFieldHandler{Source: rv.JSON, Field: "/items/0/id"}))
+			})
+
+			It("rejects invalid JSON pointer escapes", func() {
+				_, err := rv.NewSourceFieldHandler([]string{"json./a~2b"})
+				Expect(err).To(HaveOccurred())
+			})
+
 			It("accepts valid sources", func() {
 				Expect(rv.NewSourceFieldHandler([]string{"path.foo"})).To(Equal(rv.SourceFieldHandler{Source: rv.PATH, Field: "foo"}))
 				Expect(rv.NewSourceFieldHandler([]string{"query.foo"})).To(Equal(rv.SourceFieldHandler{Source: rv.QUERY, Field: "foo"}))
 				Expect(rv.NewSourceFieldHandler([]string{"json.foo"})).To(Equal(rv.SourceFieldHandler{Source: rv.JSON, Field: "foo"}))
 				Expect(rv.NewSourceFieldHandler([]string{"form.foo"})).To(Equal(rv.SourceFieldHandler{Source: rv.FORM, Field: "foo"}))
+				Expect(rv.NewSourceFieldHandler([]string{"header.foo"})).To(Equal(rv.SourceFieldHandler{Source: rv.HEADER, Field: "foo"}))
+				Expect(rv.NewSourceFieldHandler([]string{"cookie.foo"})).To(Equal(rv.SourceFieldHandler{Source: rv.COOKIE, Field: "foo"}))
+				Expect(rv.NewSourceFieldHandler([]string{"file.foo"})).To(Equal(rv.SourceFieldHandler{Source: rv.FILE, Field: "foo"}))
 			})
 		})
 
@@ -63,18 +92,170 @@ var _ = Describe("Validators", func() {
 				Expect(field.Value).To(Equal("flah"))
 			})
 
+			Context("With repeated query keys", func() {
+				BeforeEach(func() {
+					req.Query = "id=1&id=2&id=3"
+				})
+
+				It("pulls the first value for single fields", func() {
+					rv.SourceFieldHandler{Source: rv.QUERY, Field: "id"}.Run(req, field)
+					Expect(field.Value).To(Equal("1"))
+				})
+
+				It("pulls every value for list fields", func() {
+					rv.SourceFieldHandler{Source: rv.QUERY, Field: "id", List: true}.Run(req, field)
+					Expect(field.Value).To(Equal([]string{"1", "2", "3"}))
+				})
+
+				It("returns an error when repeated keys are rejected", func() {
+					rv.SourceFieldHandler{Source: rv.QUERY, Field: "id", RejectRepeated: true}.Run(req, field)
+					Expect(field.Value).To(BeNil())
+					Expect(field.Errors).To(Equal([]error{&rv.ValidationError{
+						Rule:    "repeated",
+						Value:   []string{"1", "2", "3"},
+						Message: "'id' given 3 times, expected once",
+					}}))
+				})
+
+				It("accepts a single value when repeated keys are rejected", func() {
+					req.Query = "id=1"
+					rv.SourceFieldHandler{Source: rv.QUERY, Field: "id", RejectRepeated: true}.Run(req, field)
+					Expect(field.Errors).To(BeEmpty())
+					Expect(field.Value).To(Equal("1"))
+				})
+			})
+
 			It("properly pulls fields from JSON body arguments", func() {
 				req.Body = `{"one": 2}`
 				rv.SourceFieldHandler{Source: rv.JSON, Field: "one"}.Run(req, field)
 				Expect(field.Value).To(Equal(2.0))
 			})
 
+			Context("With a nested JSON body", func() {
+				BeforeEach(func() {
+					req.Body = `{"user": {"email": "a@b.c", "name": null}, "items": [{"id": 7}, {"id": 8}], "a/b": {"~c": 1}}`
+				})
+
+				It("follows dotted paths", func() {
+					rv.SourceFieldHandler{Source: rv.JSON, Field: "user.email"}.Run(req, field)
+					Expect(field.Errors).To(BeEmpty())
+					Expect(field.Value).To(Equal("a@b.c"))
+				})
+
+				It("follows array indexes", func() {
+					rv.SourceFieldHandler{Source: rv.JSON, Field: "items.1.id"}.Run(req, field)
+					Expect(field.Errors).To(BeEmpty())
+					Expect(field.Value).To(Equal(8.0))
+				})
+
+				It("follows JSON pointers with escapes", func() {
+					rv.SourceFieldHandler{Source: rv.JSON, Field: "/a~1b/~0c"}.Run(req, field)
+					Expect(field.Errors).To(BeEmpty())
+					Expect(field.Value).To(Equal(1.0))
+				})
+
+				It("leaves the value unset when the path is not found", func() {
+					for _, path := range []string{"user.phone", "items.5.id", "missing.deeply", "user.name.first"} {
+						rv.SourceFieldHandler{Source: rv.JSON, Field: path}.Run(req, field)
+					}
+					Expect(field.Errors).To(BeEmpty())
+					Expect(field.Value).To(BeNil())
+				})
+
+				It("returns an error when the path goes through the wrong type", func() {
+					rv.SourceFieldHandler{Source: rv.JSON, Field: "user.email.domain"}.Run(req, field)
+					rv.SourceFieldHandler{Source: rv.JSON, Field: "items.first.id"}.Run(req, field)
+					Expect(field.Value).To(BeNil())
+					Expect(field.Errors).To(HaveLen(2))
+					Expect(field.Errors[0]).To(MatchError("expected object or array at 'user.email' in path 'user.email.domain', got string"))
+					Expect(field.Errors[1]).To(MatchError("expected array index at 'items' in path 'items.first.id', got 'first'"))
+				})
+			})
+
 			It("properly pulls fields from form body arguments", func() {
 				req.Body = `one=two&three=four`
 				rv.SourceFieldHandler{Source: rv.FORM, Field: "three"}.Run(req, field)
 				Expect(field.Value).To(Equal("four"))
 			})
 
+			It("properly pulls fields from headers regardless of case", func() {
+				rv.SourceFieldHandler{Source: rv.HEADER, Field: "x-request-id"}.Run(req, field)
+				Expect(field.Value).To(Equal("abc123"))
+			})
+
+			It("pulls the first value of a multi-valued header for single fields", func() {
+				rv.SourceFieldHandler{Source: rv.HEADER, Field: "Accept"}.Run(req, field)
+				Expect(field.Value).To(Equal("text/html"))
+			})
+
+			It("pulls every value of a multi-valued header for list fields", func() {
+				rv.SourceFieldHandler{Source: rv.HEADER, Field: "Accept", List: true}.Run(req, field)
+				Expect(field.Value).To(Equal([]string{"text/html", "application/json"}))
+			})
+
+			It("finds headers in maps with non-canonical keys", func() {
+				req.Header = http.Header{"x-lower": []string{"yes"}}
+				rv.SourceFieldHandler{Source: rv.HEADER, Field: "X-Lower"}.Run(req, field)
+				Expect(field.Value).To(Equal("yes"))
+			})
+
+			It("properly pulls fields from cookies", func() {
+				rv.SourceFieldHandler{Source: rv.COOKIE, Field: "session"}.Run(req, field)
+				Expect(field.Value).To(Equal("s3cr3t"))
+			})
+
+			Context("With a multipart body", func() {
+				BeforeEach(func() {
+					var contentType string
+					req.Body, contentType = multipartBody(map[string]string{"caption": "hi"},
+						testFile{"photos", "a.jpg", "image/jpeg", "aaa"},
+						testFile{"photos", "b.jpg", "image/jpeg", "bbbb"})
+					req.Header = http.Header{"Content-Type": []string{contentType}}
+				})
+
+				It("pulls the first file for single fields", func() {
+					rv.SourceFieldHandler{Source: rv.FILE, Field: "photos"}.Run(req, field)
+					Expect(field.Errors).To(BeEmpty())
+					Expect(field.Value.(*multipart.FileHeader).Filename).To(Equal("a.jpg"))
+				})
+
+				It("pulls every file for list fields", func() {
+					rv.SourceFieldHandler{Source: rv.FILE, Field: "photos", List: true}.Run(req, field)
+					Expect(field.Errors).To(BeEmpty())
+					Expect(field.Value).To(HaveLen(2))
+				})
+
+				It("pulls text parts as form values", func() {
+					rv.SourceFieldHandler{Source: rv.FORM, Field: "caption"}.Run(req, field)
+					Expect(field.Value).To(Equal("hi"))
+				})
+			})
+
+			It("reports headers, cookies and files as unavailable from Requests which don't provide them", func() {
+				plain := struct{ rv.Request }{req}
+				rv.SourceFieldHandler{Source: rv.HEADER, Field: "Accept"}.Run(plain, field)
+				rv.SourceFieldHandler{Source: rv.COOKIE, Field: "session"}.Run(plain, field)
+				Expect(field.Value).To(BeNil())
+				Expect(field.Errors).To(ConsistOf(
+					MatchError("struct { rv.Request } doesn't provide headers"),
+					MatchError("struct { rv.Request } doesn't provide cookies")))
+
+				field = &rv.Field{}
+				rv.SourceFieldHandler{Source: rv.FILE, Field: "upload"}.Run(plain, field)
+				Expect(field.Errors).To(ConsistOf(MatchError("struct { rv.Request } doesn't provide multipart bodies")))
+
+				field = &rv.Field{}
+				rv.SourceFieldHandler{Source: rv.QUERY, Field: "q"}.Run(plain, field)
+				Expect(field.Errors).To(BeEmpty())
+			})
+
+			It("leaves the value unset for missing headers and cookies", func() {
+				rv.SourceFieldHandler{Source: rv.HEADER, Field: "X-Missing"}.Run(req, field)
+				rv.SourceFieldHandler{Source: rv.COOKIE, Field: "missing"}.Run(req, field)
+				Expect(field.Value).To(BeNil())
+				Expect(field.Errors).To(BeEmpty())
+			})
+
 		})
 	})
 
@@ -153,6 +334,23 @@ var _ = Describe("Validators", func() {
 
 				tm{"float32", 42.0, float32(42.0)},
 
+				tm{"int", json.Number("42"), 42},
+				tm{"int8", json.Number("-42"), int8(-42)},
+				tm{"int64", json.Number("9007199254740993"), int64(9007199254740993)},
+				tm{"int64", json.Number("-9223372036854775808"), int64(math.MinInt64)},
+				tm{"int", json.Number("2.0"), 2},
+				tm{"int", json.Number("1.5e3"), 1500},
+				tm{"int", json.Number("0.0e10"), 0},
+				tm{"int", json.Number("0e1000000"), 0},
+				tm{"int", json.Number("0.0012e4"), 12},
+				tm{"uint64", json.Number("18446744073709551615"), uint64(math.MaxUint64)},
+				tm{"uint16", json.Number("12e2"), uint16(1200)},
+				tm{"int", float64(42), 42},
+				tm{"uint", float64(42), uint(42)},
+				tm{"float64", json.Number("4.2"), 4.2},
+				tm{"float32", json.Number("4.2"), float32(4.2)},
+				tm{"string", json.Number("12345678901234567890"), "12345678901234567890"},
+
 				tm{"string", "yarp", "yarp"},
 				tm{"string", false, "false"},
 				tm{"string", true, "true"},
@@ -228,8 +426,25 @@ var _ = Describe("Validators", func() {
 				tm{"uint32", int(-42), nil},
 				tm{"uint64", int32(-42), nil},
 
+				tm{"int", json.Number("2.5"), nil},
+				tm{"int", json.Number("25e-1"), nil},
+				tm{"int", json.Number("1e-400"), nil},
+				tm{"int8", json.Number("128"), nil},
+				tm{"int64", json.Number("9223372036854775808"), nil},
+				tm{"int64", json.Number("1e400"), nil},
+				tm{"uint", json.Number("-1"), nil},
+				tm{"uint64", json.Number("18446744073709551616"), nil},
+				tm{"int", json.Number("1e1000000"), nil},
+				tm{"int", json.Number("1e-1000000"), nil},
+				tm{"int", float64(2.5), nil},
+				tm{"int64", float64(1 << 54), nil},
+				tm{"uint8", float64(256), nil},
+
 				tm{"float32", "blar", nil},
 				tm{"float64", "blar", nil},
+				tm{"float32", json.Number("1e39"), nil},
+				tm{"float64", json.Number("1e400"), nil},
+				tm{"float32", float64(1e39), nil},
 			} {
 				ttype, from := tc.ttype, tc.from
 				It(fmt.Sprintf("cannot coerce %T(%v) to %s", from, from, ttype), func() {
@@ -238,9 +453,204 @@ var _ = Describe("Validators", func() {
 					Expect(field.Value).To(Equal(from))
 					Expect(field.Errors).ToNot(BeEmpty())
 					Expect(field.Errors[0]).To(HaveOccurred())
+					ve := field.Errors[0].(*rv.ValidationError)
+					Expect(ve.Rule).To(Equal("type"))
+					Expect(ve.Value).To(Equal(from))
+					Expect(ve.Params).To(Equal([]string{ttype}))
 				})
 			}
 
+			Context("With the default NumberFormat", func() {
+				for _, tc := range []tm{
+					tm{"int", "010", 10},
+					tm{"uint", "+7", uint(7)},
+					tm{"float64", "0.5", 0.5},
+				} {
+					ttype, from, to := tc.ttype, tc.from, tc.to
+					It(fmt.Sprintf("parses %#v as a decimal %s", from, ttype), func() {
+						field.Value = from
+						rv.TypeHandler{Type: ttype}.Run(req, field)
+						Expect(field.Errors).To(BeEmpty())
+						Expect(field.Value).To(Equal(to))
+					})
+				}
+
+				for _, tc := range []struct{ ttype, from, err string }{
+					{"int", "0x1F", "hexadecimal numbers are not allowed, got '0x1F'"},
+					{"int", "-0o17", "octal numbers are not allowed, got '-0o17'"},
+					{"uint", "0b101", "binary numbers are not allowed, got '0b101'"},
+					{"int", "1_000", `strconv.ParseInt: parsing "1_000": invalid syntax`},
+					{"float64", "NaN", "float NaN is not allowed"},
+					{"float32", "-Inf", "float -Inf is not allowed"},
+					{"float64", "1e400", `strconv.ParseFloat: parsing "1e400": value out of range`},
+				} {
+					ttype, from, msg := tc.ttype, tc.from, tc.err
+					It(fmt.Sprintf("rejects %#v for %s", from, ttype), func() {
+						field.Value = from
+						rv.TypeHandler{Type: ttype}.Run(req, field)
+						Expect(field.Value).To(Equal(from))
+						Expect(field.Errors).To(ConsistOf(MatchError(msg)))
+					})
+				}
+			})
+
+			Context("With a NumberFormat", func() {
+				nf := rv.NumberFormat{Hex: true, Octal: true, Binary: true, NoLeadingZeros: true, NonFinite: true}
+
+				for _, tc := range []tm{
+					tm{"int", "0x1F", 31},
+					tm{"int", "-0X1f", -31},
+					tm{"int", "0o17", 15},
+					tm{"uint8", "0b101", uint8(5)},
+					tm{"int", "0", 0},
+					tm{"float64", "Inf", math.Inf(1)},
+					tm{"float32", "-Inf", float32(math.Inf(-1))},
+				} {
+					ttype, from, to := tc.ttype, tc.from, tc.to
+					It(fmt.Sprintf("parses %#v as %s", from, ttype), func() {
+						field.Value = from
+						rv.TypeHandler{Type: ttype, Numbers: nf}.Run(req, field)
+						Expect(field.Errors).To(BeEmpty())
+						Expect(field.Value).To(Equal(to))
+					})
+				}
+
+				It("accepts NaN", func() {
+					field.Value = "NaN"
+					rv.TypeHandler{Type: "float64", Numbers: nf}.Run(req, field)
+					Expect(field.Errors).To(BeEmpty())
+					Expect(math.IsNaN(field.Value.(float64))).To(BeTrue())
+				})
+
+				It("rejects leading zeros", func() {
+					for _, from := range []string{"010", "-007", "00.5"} {
+						f := &rv.Field{Value: from}
+						rv.TypeHandler{Type: "float64", Numbers: nf}.Run(req, f)
+						Expect(f.Errors).To(ConsistOf(MatchError(fmt.Sprintf("leading zeros are not allowed, got '%s'", from))))
+					}
+				})
+			})
+		})
+	})
+
+	Describe("FieldHandlers", func() {
+		It("runs every handler in order", func() {
+			first, second := &mockHandler{}, &mockHandler{}
+			field.Value = "x"
+			rv.FieldHandlers{first, second}.Run(req, field)
+			Expect(first.called).To(Equal(1))
+			Expect(second.called).To(Equal(1))
+		})
+
+		It("stops when a handler halts the field", func() {
+			validator := &mockHandler{}
+			field.Value = "x"
+			rv.FieldHandlers{rv.TypeHandler{Type: "int"}, validator}.Run(req, field)
+			Expect(field.Halt).To(BeTrue())
+			Expect(field.Errors).To(HaveLen(1))
+			Expect(validator.called).To(Equal(0))
+		})
+	})
+
+	Describe("StrictTypeHandler", func() {
+		It("accepts values of the JSON type", func() {
+			field.Value = json.Number("5")
+			rv.StrictTypeHandler{JSONType: "number"}.Run(req, field)
+			Expect(field.Errors).To(BeEmpty())
+			Expect(field.Value).To(Equal(json.Number("5")))
+		})
+
+		It("rejects values of other JSON types", func() {
+			field.Value = "5"
+			rv.StrictTypeHandler{JSONType: "number"}.Run(req, field)
+			Expect(field.Errors).To(Equal([]error{&rv.ValidationError{
+				Rule:    "type",
+				Value:   "5",
+				Params:  []string{"number"},
+				Message: "expected number, got string",
+			}}))
+		})
+
+		It("checks the elements of arrays and objects", func() {
+			field.Value = []interface{}{true, "no", false}
+			rv.StrictTypeHandler{JSONType: "array", ElemJSONType: "boolean"}.Run(req, field)
+			Expect(field.Errors).To(BeEmpty())
+			Expect(field.Fields).To(HaveLen(1))
+			Expect(field.Fields["[1]"].Errors).To(ConsistOf(MatchError("expected boolean, got string")))
+
+			field = &rv.Field{Value: map[string]interface{}{"a": "x", "b": nil}}
+			rv.StrictTypeHandler{JSONType: "object", ElemJSONType: "string"}.Run(req, field)
+			Expect(field.Fields).To(HaveLen(1))
+			Expect(field.Fields["[b]"].Errors).To(ConsistOf(MatchError("expected string, got null")))
+		})
+
+		It("ignores missing values", func() {
+			rv.StrictTypeHandler{JSONType: "number"}.Run(req, field)
+			Expect(field.Errors).To(BeEmpty())
+		})
+	})
+
+	Describe("MaxSizeHandler", func() {
+		Describe("NewMaxSizeHandler", func() {
+			It("accepts sizes in bytes with optional suffixes", func() {
+				Expect(rv.NewMaxSizeHandler([]string{"100"})).To(Equal(rv.MaxSizeHandler{Size: 100}))
+				Expect(rv.NewMaxSizeHandler([]string{"100B"})).To(Equal(rv.MaxSizeHandler{Size: 100}))
+				Expect(rv.NewMaxSizeHandler([]string{"2KB"})).To(Equal(rv.MaxSizeHandler{Size: 2048}))
+				Expect(rv.NewMaxSizeHandler([]string{"3mb"})).To(Equal(rv.MaxSizeHandler{Size: 3 << 20}))
+				Expect(rv.NewMaxSizeHandler([]string{"1GB"})).To(Equal(rv.MaxSizeHandler{Size: 1 << 30}))
+			})
+
+			It("rejects invalid sizes", func() {
+				_, err := rv.NewMaxSizeHandler([]string{"lots"})
+				Expect(err).To(HaveOccurred())
+				_, err = rv.NewMaxSizeHandler([]string{"1", "2"})
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Describe("Run", func() {
+			It("returns no error for files within the limit", func() {
+				field.Value = &multipart.FileHeader{Filename: "a.txt", Size: 10}
+				rv.MaxSizeHandler{Size: 10}.Run(req, field)
+				Expect(field.Errors).To(BeEmpty())
+			})
+
+			It("returns an error for files over the limit", func() {
+				field.Value = &multipart.FileHeader{Filename: "a.txt", Size: 11}
+				rv.MaxSizeHandler{Size: 10}.Run(req, field)
+				Expect(field.Errors).ToNot(BeEmpty())
+			})
+
+			It("ignores missing files", func() {
+				rv.MaxSizeHandler{Size: 10}.Run(req, field)
+				Expect(field.Errors).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("ContentTypesHandler", func() {
+		file := func(contentType string) *multipart.FileHeader {
+			return &multipart.FileHeader{Header: textproto.MIMEHeader{"Content-Type": []string{contentType}}}
+		}
+
+		Describe("Run", func() {
+			It("returns no error for allowed content types", func() {
+				field.Value = file("image/PNG")
+				rv.ContentTypesHandler{Types: []string{"image/jpeg", "image/png"}}.Run(req, field)
+				Expect(field.Errors).To(BeEmpty())
+			})
+
+			It("allows wildcard subtypes", func() {
+				field.Value = file("image/gif")
+				rv.ContentTypesHandler{Types: []string{"image/*"}}.Run(req, field)
+				Expect(field.Errors).To(BeEmpty())
+			})
+
+			It("returns an error for other content types", func() {
+				field.Value = file("application/pdf")
+				rv.ContentTypesHandler{Types: []string{"image/*"}}.Run(req, field)
+				Expect(field.Errors).ToNot(BeEmpty())
+			})
 		})
 	})
 
@@ -322,6 +732,17 @@ var _ = Describe("Validators", func() {
 				Expect(field.Errors[0]).To(HaveOccurred())
 			})
 
+			It("returns a ValidationError describing the range", func() {
+				field.Value = 11
+				rv.RangeHandler{Start: "1", End: "10"}.Run(req, field)
+				Expect(field.Errors).To(Equal([]error{&rv.ValidationError{
+					Rule:    "range",
+					Value:   11,
+					Params:  []string{"1", "10"},
+					Message: "11 not in range 1, 10",
+				}}))
+			})
+
 			It("returns an error if the range is not valid", func() {
 				field.Value = 5
 				rv.RangeHandler{Start: "one", End: "10"}.Run(req, field)
@@ -349,6 +770,15 @@ var _ = Describe("Validators", func() {
 				Expect(field.Errors[0]).To(HaveOccurred())
 			})
 
+			It("returns a ValidationError listing the options", func() {
+				field.Value = "five"
+				rv.OptionsHandler{Options: map[string]struct{}{"one": y, "two": y}}.Run(req, field)
+				ve := field.Errors[0].(*rv.ValidationError)
+				Expect(ve.Rule).To(Equal("options"))
+				Expect(ve.Value).To(Equal("five"))
+				Expect(ve.Params).To(Equal([]string{"one", "two"}))
+			})
+
 			It("works on ints", func() {
 				field.Value = 5
 				rv.OptionsHandler{Options: map[string]struct{}{"1": y, "2": y, "3": y}}.Run(req, field)
@@ -434,6 +864,21 @@ var _ = Describe("Validators", func() {
 
 			})
 
+			Context("When only some items can be converted", func() {
+				BeforeEach(func() {
+					handler = rv.ListHandler{SubHandlers: rv.FieldHandlers{
+						rv.TypeHandler{Type: "int"},
+					}}
+					field.Value = "1,x,3"
+					handler.Run(req, field)
+				})
+
+				It("reports the errors and leaves the value alone", func() {
+					Expect(field.Errors).To(HaveLen(1))
+					Expect(field.Value).To(Equal("1,x,3"))
+				})
+			})
+
 			Context("When there is a type sub-handler", func() {
 				BeforeEach(func() {
 					handler = rv.ListHandler{SubHandlers: rv.FieldHandlers{
@@ -453,6 +898,27 @@ var _ = Describe("Validators", func() {
 					Expect(field.Value).To(Equal([]int{1, 2, 3}))
 				})
 
+				It("splits each of a list of repeated values", func() {
+					field.Value = []string{"1,2", "3"}
+					handler.Run(req, field)
+					Expect(field.Value).To(Equal([]int{1, 2, 3}))
+				})
+
+				It("splits on the Separator", func() {
+					field.Value = "1;2;3"
+					handler.Separator = ";"
+					handler.Run(req, field)
+					Expect(field.Value).To(Equal([]int{1, 2, 3}))
+				})
+
+				It("doesn't split when NoSplit is set", func() {
+					field.Value = []string{"1,2", "3"}
+					handler.NoSplit = true
+					handler.Run(req, field)
+					Expect(field.Errors).To(HaveLen(1))
+					Expect(field.Value).To(Equal([]string{"1,2", "3"}))
+				})
+
 				It("will transform a slice of ints into a slice of strings", func() {
 					field.Value = []int{1, 2, 3}
 					handler = rv.ListHandler{SubHandlers: rv.FieldHandlers{
@@ -496,6 +962,11 @@ var _ = Describe("Validators", func() {
 				Expect(field.Errors).NotTo(BeEmpty())
 				Expect(field.Errors[0]).To(HaveOccurred())
 			})
+
+			It("reports the required rule", func() {
+				rv.RequiredHandler{Required: true}.Run(req, field)
+				Expect(field.Errors[0].(*rv.ValidationError).Rule).To(Equal("required"))
+			})
 		})
 	})
 
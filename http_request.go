@@ -0,0 +1,162 @@
+package rv
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+)
+
+// PathExtractor returns the parameters a router found in the path of a
+// request, for HTTPRequest.
+type PathExtractor func(*http.Request) map[string]string
+
+// HTTPRequest reads values from a http.Request for any router, taking
+// the path parameters from PathExtractor. Supporting a new router only
+// needs a PathExtractor, for example with chi:
+//
+//	req := rv.NewHTTPRequest(r, func(r *http.Request) map[string]string {
+//		params := chi.RouteContext(r.Context()).URLParams
+//		args := make(map[string]string, len(params.Keys))
+//		for i, key := range params.Keys {
+//			args[key] = params.Values[i]
+//		}
+//		return args
+//	})
+type HTTPRequest struct {
+	*http.Request
+	PathExtractor PathExtractor
+	// MaxMemory limits how much of a multipart body is kept in memory,
+	// DefaultMaxMemory is used if it is zero.
+	MaxMemory int64
+
+	bodyRead bool
+}
+
+// NewHTTPRequest returns an HTTPRequest reading req, with path
+// parameters from pathExtractor, which may be nil if the route has
+// none.
+func NewHTTPRequest(req *http.Request, pathExtractor PathExtractor) *HTTPRequest {
+	return &HTTPRequest{Request: req, PathExtractor: pathExtractor}
+}
+
+// QueryArgs pulls the standard query args from the request.
+func (r *HTTPRequest) QueryArgs() (url.Values, error) {
+	vals, err := url.ParseQuery(r.Request.URL.RawQuery) // Doesn't use URL.Query because we want to see errors.
+	if err != nil {
+		return nil, err
+	}
+	return vals, nil
+}
+
+// PathArgs returns the path parameters found by the PathExtractor, or
+// nil if there isn't one.
+func (r *HTTPRequest) PathArgs() (map[string]string, error) {
+	if r.PathExtractor == nil {
+		return nil, nil
+	}
+	return r.PathExtractor(r.Request), nil
+}
+
+// BodyJSON parses and returns a map of values from the body.
+func (r *HTTPRequest) BodyJSON() (map[string]interface{}, error) {
+	if r.bodyRead {
+		return nil, errors.New("body already read")
+	}
+
+	r.bodyRead = true
+	return ParseJSONBody(r.Request.Body)
+}
+
+// BodyForm parses and returns any values from a body form, using the
+// text parts of a multipart body.
+func (r *HTTPRequest) BodyForm() (url.Values, error) {
+	if IsMultipart(r.Request.Header.Get("Content-Type")) {
+		form, err := r.BodyMultipart()
+		if form == nil {
+			return nil, err
+		}
+		return url.Values(form.Value), err
+	}
+
+	if r.bodyRead {
+		return nil, errors.New("body already read")
+	}
+
+	r.bodyRead = true
+
+	if r.Request.Body == nil {
+		return nil, nil
+	}
+
+	body, err := ioutil.ReadAll(r.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return url.ParseQuery(string(body))
+}
+
+// BodyMultipart parses and returns a multipart/form-data body with
+// http.Request.ParseMultipartForm, so files stored in temporary files
+// are removed by net/http when the request finishes. The parsed form is
+// kept on the http.Request so it can be requested more than once.
+func (r *HTTPRequest) BodyMultipart() (*multipart.Form, error) {
+	if r.Request.MultipartForm != nil {
+		return r.Request.MultipartForm, nil
+	}
+
+	if !IsMultipart(r.Request.Header.Get("Content-Type")) || r.Request.Body == nil {
+		return nil, nil
+	}
+
+	if r.bodyRead {
+		return nil, errors.New("body already read")
+	}
+
+	r.bodyRead = true
+
+	maxMemory := r.MaxMemory
+	if maxMemory <= 0 {
+		maxMemory = DefaultMaxMemory
+	}
+	if err := r.Request.ParseMultipartForm(maxMemory); err != nil {
+		return nil, err
+	}
+	return r.Request.MultipartForm, nil
+}
+
+// HeaderArgs returns the request headers.
+func (r *HTTPRequest) HeaderArgs() (http.Header, error) {
+	return r.Request.Header, nil
+}
+
+// CookieArgs returns the cookies sent with the request.
+func (r *HTTPRequest) CookieArgs() ([]*http.Cookie, error) {
+	return r.Request.Cookies(), nil
+}
+
+// RawBody returns the request body so CachedRequest can buffer it
+// and parse it as both JSON and a form.
+func (r *HTTPRequest) RawBody() (io.Reader, error) {
+	if r.bodyRead {
+		return nil, errors.New("body already read")
+	}
+
+	r.bodyRead = true
+	if r.Request.Body == nil {
+		return nil, nil
+	}
+	return r.Request.Body, nil
+}
+
+// Ensure *HTTPRequest meets the RawBodyRequest, HeaderRequest,
+// CookieRequest and MultipartRequest interfaces
+var (
+	_ RawBodyRequest   = (*HTTPRequest)(nil)
+	_ HeaderRequest    = (*HTTPRequest)(nil)
+	_ CookieRequest    = (*HTTPRequest)(nil)
+	_ MultipartRequest = (*HTTPRequest)(nil)
+)
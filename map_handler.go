@@ -0,0 +1,80 @@
+package rv
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// keyPrefix marks handlers in the rv tag of a map field which apply to
+// the map keys rather than the values, e.g. "key:options=status,owner".
+const keyPrefix = "key:"
+
+// MapHandler runs KeyHandlers on each key and ValueHandlers on each
+// value of a map, then builds a map of the converted keys and values.
+// Errors for an entry are kept in Field.Fields under "[key]".
+type MapHandler struct {
+	KeyHandlers   FieldHandlers
+	ValueHandlers FieldHandlers
+}
+
+func (h MapHandler) Run(req Request, field *Field) {
+	if field.Value == nil {
+		return
+	}
+
+	val := reflect.ValueOf(field.Value)
+	if val.Kind() != reflect.Map {
+		field.Errors = append(field.Errors, newValidationError("type", field.Value, []string{"map"}, "expected an object, got %T", field.Value))
+		field.Halt = true
+		return
+	}
+
+	keys := val.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+
+	var keyType, valueType reflect.Type
+	consistent := true
+	entries := make([][2]*Field, 0, len(keys))
+	for i, key := range keys {
+		keyField := &Field{Value: key.Interface()}
+		valueField := &Field{Value: val.MapIndex(key).Interface()}
+		h.KeyHandlers.Run(req, keyField)
+		h.ValueHandlers.Run(req, valueField)
+
+		name := fmt.Sprintf("[%v]", key)
+		if errs := append(append([]error{}, keyField.Errors...), valueField.Errors...); len(errs) > 0 {
+			addNestedField(field, name, Field{Value: valueField.Value, Errors: errs})
+		}
+		for path, nested := range valueField.Fields {
+			nestSources(nested.Errors, []string{fmt.Sprint(key)})
+			addNestedField(field, joinPath(name, path), nested)
+		}
+
+		if i == 0 {
+			keyType, valueType = reflect.TypeOf(keyField.Value), reflect.TypeOf(valueField.Value)
+		}
+		if reflect.TypeOf(keyField.Value) != keyType || reflect.TypeOf(valueField.Value) != valueType {
+			consistent = false
+		}
+		entries = append(entries, [2]*Field{keyField, valueField})
+	}
+
+	// Leave the value alone if the entries didn't all end up the same types
+	if len(entries) == 0 || !consistent || keyType == nil || valueType == nil {
+		return
+	}
+
+	result := reflect.MakeMap(reflect.MapOf(keyType, valueType))
+	for _, entry := range entries {
+		result.SetMapIndex(reflect.ValueOf(entry[0].Value), reflect.ValueOf(entry[1].Value))
+	}
+	field.Value = result.Interface()
+}
+
+func addNestedField(field *Field, path string, nested Field) {
+	if field.Fields == nil {
+		field.Fields = make(map[string]Field)
+	}
+	field.Fields[path] = nested
+}
@@ -0,0 +1,97 @@
+package rv
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// boundKey is the context key the middleware stores a bound struct
+// under, one for each struct type so middleware can be stacked.
+type boundKey struct {
+	reflect.Type
+}
+
+// BindMiddleware creates a RequestHandler for the type of field and
+// returns a net/http middleware which binds a new value of that type
+// from each request, storing it in the request context for Bound to
+// return. newRequest turns the http.Request into a Request for the
+// router in use, such as with NewHTTPRequest and a PathExtractor. If the
+// request doesn't bind, errorWriter is called instead of the next
+// handler, ErrorWriter if none is given.
+//
+//	mw := rv.BindMiddleware(itemArgs{}, func(r *http.Request) rv.Request {
+//		return rv.NewHTTPRequest(r, chiParams)
+//	})
+func BindMiddleware(field interface{}, newRequest func(*http.Request) Request, errorWriter ...func(http.ResponseWriter, error, FieldErrors)) func(http.Handler) http.Handler {
+	argHandler, err := NewRequestHandler(field)
+	if err != nil {
+		panic("Unable to create RequestHandler: " + err.Error())
+	}
+	return bindMiddleware(reflect.TypeOf(field), argHandler.RunOrdered, newRequest, errorWriter)
+}
+
+// BindAllMiddleware is like BindMiddleware, but creates a MultiHandler
+// for the container type, filling every struct field of a new container
+// whose type has rv tags, such as separate path, query and body
+// structs. The errors for all of the fields are written at once.
+func BindAllMiddleware(container interface{}, newRequest func(*http.Request) Request, errorWriter ...func(http.ResponseWriter, error, FieldErrors)) func(http.Handler) http.Handler {
+	multiHandler, err := NewMultiHandler(container)
+	if err != nil {
+		panic("Unable to create MultiHandler: " + err.Error())
+	}
+	return bindMiddleware(reflect.TypeOf(container), multiHandler.RunOrdered, newRequest, errorWriter)
+}
+
+// bindMiddleware returns a middleware binding new values of t with run.
+func bindMiddleware(t reflect.Type, run func(Request, interface{}) (error, FieldErrors), newRequest func(*http.Request) Request, errorWriter []func(http.ResponseWriter, error, FieldErrors)) func(http.Handler) http.Handler {
+	if len(errorWriter) < 1 {
+		errorWriter = append(errorWriter, ErrorWriter)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			if r.MultipartForm == nil {
+				// net/http only removes the temporary files of forms
+				// parsed on the request it created, not on copies
+				defer func() {
+					if r.MultipartForm != nil {
+						r.MultipartForm.RemoveAll()
+					}
+				}()
+			}
+			val := reflect.New(t)
+			err, fieldErrors := run(newRequest(r), val.Interface())
+			if err != nil || len(fieldErrors) > 0 {
+				errorWriter[0](rw, err, fieldErrors)
+				return
+			}
+			ctx := context.WithValue(r.Context(), boundKey{t}, val.Elem().Interface())
+			next.ServeHTTP(rw, r.WithContext(ctx))
+		})
+	}
+}
+
+// Bound returns the T bound by a BindMiddleware or BindAllMiddleware for
+// T which the request has passed through.
+func Bound[T any](r *http.Request) (T, bool) {
+	val, ok := r.Context().Value(boundKey{reflect.TypeOf((*T)(nil)).Elem()}).(T)
+	return val, ok
+}
+
+// ErrorWriter is the default error writer for BindMiddleware. It
+// panics on argument errors and writes the field errors with a 400
+// status, in declaration order.
+func ErrorWriter(rw http.ResponseWriter, argErr error, fieldErrors FieldErrors) {
+	if argErr != nil {
+		panic(argErr)
+	}
+
+	rw.WriteHeader(http.StatusBadRequest)
+	for _, field := range fieldErrors {
+		for _, err := range field.Errors {
+			fmt.Fprintln(rw, field.Name, err)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package rv
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// MultiHandler fills every struct field of a container whose type has
+// rv tags, such as a handler context carrying separate PathParams,
+// QueryParams and Body structs, in a single pass over the request.
+// Fields with errors are named by their path in the container, e.g.
+// "Body.Address.Zip".
+type MultiHandler struct {
+	containerType reflect.Type
+	parts         []boundField
+}
+
+// boundField is a container field filled by a MultiHandler.
+type boundField struct {
+	name    string
+	index   int
+	ptr     bool
+	handler *RequestHandler
+}
+
+// NewMultiHandler builds a MultiHandler for the container type, which
+// may be given as a struct or a pointer to one. The options apply to
+// the RequestHandlers for every field, and problems with the tags of
+// all the fields are returned together as TagErrors.
+func NewMultiHandler(container interface{}, options ...Option) (*MultiHandler, error) {
+	containerType := derefType(reflect.TypeOf(container))
+	if containerType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Expected struct, got %s", containerType.Kind())
+	}
+
+	h := &MultiHandler{containerType: containerType}
+	var tagErrs TagErrors
+	for i := 0; i < containerType.NumField(); i++ {
+		field := containerType.Field(i)
+		if field.PkgPath != "" || field.Tag.Get("rv") != "" {
+			continue
+		}
+		fieldType := field.Type
+		isPtr := fieldType.Kind() == reflect.Ptr
+		if isPtr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() != reflect.Struct || fieldType == timeType {
+			continue
+		}
+		if !hasTags(fieldType) && !hasEmbeddedTags(fieldType) {
+			continue
+		}
+
+		handler, err := newRequestHandler(reflect.Zero(fieldType).Interface(), options, map[reflect.Type]*RequestHandler{})
+		if errs, ok := err.(TagErrors); ok {
+			tagErrs = append(tagErrs, newTagErrors(field.Name, errs)...)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		h.parts = append(h.parts, boundField{name: field.Name, index: i, ptr: isPtr, handler: handler})
+	}
+	if len(tagErrs) > 0 {
+		return nil, tagErrs
+	}
+	if len(h.parts) == 0 {
+		return nil, fmt.Errorf("No fields with rv tags found in %v", containerType)
+	}
+	return h, nil
+}
+
+// hasTags reports whether any field of the struct type has an rv tag.
+func hasTags(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("rv") != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasEmbeddedTags reports whether any embedded struct field of the
+// struct type has rv tags, which newRequestHandler reports as errors.
+func hasEmbeddedTags(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if embeddedTags(t.Field(i), map[reflect.Type]bool{}) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Run fills each of the container's rv-tagged struct fields from the
+// request, allocating pointer fields which are nil, and returns the
+// fields with errors keyed by their path in the container.
+func (h *MultiHandler) Run(req Request, container interface{}) (argErr error, fieldErrors map[string]Field) {
+	argErr, errs := h.RunOrdered(req, container)
+	if argErr != nil {
+		return argErr, nil
+	}
+	return nil, errs.Map()
+}
+
+// RunOrdered is like Run, but fills the container's fields in
+// declaration order and returns the fields with errors in that order.
+func (h *MultiHandler) RunOrdered(req Request, container interface{}) (argErr error, fieldErrors FieldErrors) {
+	val := reflect.ValueOf(container)
+	if val.Type().Kind() != reflect.Ptr || val.Type().Elem() != h.containerType {
+		return fmt.Errorf("Expected *%v, got %v", h.containerType, val.Type()), nil
+	}
+	val = val.Elem()
+
+	// Every field has the same options, so they all share one cache
+	first := h.parts[0].handler
+	req = NewCachedRequest(req, first.maxBodySize)
+
+	for _, part := range h.parts {
+		fv := val.Field(part.index)
+		if part.ptr {
+			if fv.IsNil() {
+				fv.Set(reflect.New(fv.Type().Elem()))
+			}
+		} else {
+			fv = fv.Addr()
+		}
+
+		argErr, errs := part.handler.RunOrdered(req, fv.Interface())
+		if argErr != nil {
+			return argErr, nil
+		}
+		for _, fe := range errs {
+			fe.Name = joinPath(part.name, fe.Name)
+			for i, err := range fe.Errors {
+				if ve, ok := err.(*ValidationError); ok {
+					prefixed := *ve
+					prefixed.Field = joinPath(part.name, ve.Field)
+					fe.Errors[i] = &prefixed
+				}
+			}
+			fieldErrors = append(fieldErrors, fe)
+		}
+		if first.failFast && len(fieldErrors) > 0 {
+			break
+		}
+	}
+
+	return nil, fieldErrors
+}
+
+// Fill is like Run, but returns a single error: either the argument
+// error, or ValidationErrors holding the errors for every field.
+func (h *MultiHandler) Fill(req Request, container interface{}) error {
+	argErr, fieldErrors := h.RunOrdered(req, container)
+	if argErr != nil {
+		return argErr
+	}
+	if len(fieldErrors) > 0 {
+		return newValidationErrors(fieldErrors)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package rv
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy turns a Go field name into the name of the value in
+// the request, for fields whose tag gives a source without a name, like
+// rv:"query".
+type NamingStrategy func(fieldName string) string
+
+// WithNaming sets the NamingStrategy for fields whose tag gives a source
+// without a name and which have no json, form or schema tag to take the
+// name from. Without one, the Go field name is used as it is.
+func WithNaming(naming NamingStrategy) Option {
+	return func(h *RequestHandler) error {
+		h.naming = naming
+		return nil
+	}
+}
+
+// SnakeCase names ZipCode "zip_code" and UserID "user_id".
+func SnakeCase(fieldName string) string {
+	return strings.Join(lowerWords(fieldName), "_")
+}
+
+// KebabCase names ZipCode "zip-code" and UserID "user-id".
+func KebabCase(fieldName string) string {
+	return strings.Join(lowerWords(fieldName), "-")
+}
+
+// CamelCase names ZipCode "zipCode" and UserID "userId".
+func CamelCase(fieldName string) string {
+	words := lowerWords(fieldName)
+	for i := 1; i < len(words); i++ {
+		runes := []rune(words[i])
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, "")
+}
+
+// lowerWords splits a Go name into lower case words, keeping acronyms
+// together, so "HTTPServerID2" is "http", "server", "id2".
+func lowerWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i := 0; i <= len(runes); i++ {
+		boundary := i == len(runes) || runes[i] == '_'
+		if !boundary && i > start && unicode.IsUpper(runes[i]) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			boundary = !unicode.IsUpper(prev) || nextLower
+		}
+		if !boundary {
+			continue
+		}
+		if i > start {
+			words = append(words, strings.ToLower(string(runes[start:i])))
+		}
+		start = i
+		if i < len(runes) && runes[i] == '_' {
+			start++
+		}
+	}
+	return words
+}
+
+// nameTags are the struct tags a source without a name takes the name
+// from, in order of preference.
+var nameTags = map[string][]string{
+	"json":  {"json"},
+	"query": {"form", "schema"},
+	"form":  {"form", "schema"},
+	"file":  {"form", "schema"},
+}
+
+// inferSourceName fills in the name for a source given without one,
+// like "json", from the field's other struct tags or naming, leaving
+// sources which have a name alone.
+func inferSourceName(source string, field reflect.StructField, naming NamingStrategy) string {
+	if strings.Contains(source, ".") {
+		return source
+	}
+	if _, ok := sourceMap[source]; !ok {
+		return source
+	}
+
+	name := ""
+	for _, tag := range nameTags[source] {
+		name = strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+		if name == "-" {
+			name = ""
+		}
+		if name != "" {
+			break
+		}
+	}
+	if name == "" && naming != nil {
+		name = naming(field.Name)
+	}
+	if name == "" {
+		name = field.Name
+	}
+
+	// The name is a single key, so JSON names which would be read as
+	// paths are written as a JSON Pointer.
+	if source == "json" && (strings.Contains(name, ".") || strings.HasPrefix(name, "/")) {
+		name = "/" + strings.Replace(strings.Replace(name, "~", "~0", -1), "/", "~1", -1)
+	}
+	return source + "." + name
+}
@@ -0,0 +1,79 @@
+//go:build go1.23
+
+// Package nethttp binds rv structs from plain net/http requests, using
+// the path values of patterns registered on an http.ServeMux. It needs
+// Go 1.23, which added http.Request.Pattern.
+package nethttp
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/OwnLocal/rv"
+)
+
+// Request reads a http.Request routed by an http.ServeMux. Make one
+// with NewRequest so the path values are read.
+type Request = rv.HTTPRequest
+
+// NewRequest returns a Request reading r, with the path values of the
+// http.ServeMux pattern which matched it.
+func NewRequest(r *http.Request) *Request {
+	return rv.NewHTTPRequest(r, PathValues)
+}
+
+// PathValues returns the values of the wildcards in the http.ServeMux
+// pattern which matched the request, such as "id" in "GET /items/{id}".
+// It returns nil if the request wasn't routed by an http.ServeMux.
+func PathValues(r *http.Request) map[string]string {
+	names := patternWildcards(r.Pattern)
+	if len(names) == 0 {
+		return nil
+	}
+	pathMap := make(map[string]string, len(names))
+	for _, name := range names {
+		pathMap[name] = r.PathValue(name)
+	}
+	return pathMap
+}
+
+// patternWildcards returns the names of the wildcards in a ServeMux
+// pattern, leaving out the "{$}" end anchor.
+func patternWildcards(pattern string) []string {
+	var names []string
+	for {
+		start := strings.Index(pattern, "{")
+		if start < 0 {
+			return names
+		}
+		end := strings.Index(pattern[start:], "}")
+		if end < 0 {
+			return names
+		}
+		name := strings.TrimSuffix(pattern[start+1:start+end], "...")
+		if name != "$" && name != "" {
+			names = append(names, name)
+		}
+		pattern = pattern[start+end+1:]
+	}
+}
+
+// newRequest is the newRequest function for rv.BindMiddleware.
+func newRequest(r *http.Request) rv.Request {
+	return NewRequest(r)
+}
+
+// BindMiddleware is rv.BindMiddleware for http.ServeMux, storing the
+// bound value for rv.Bound. The wrapped handler must be registered on
+// an http.ServeMux so the path values can be read, for example
+//
+//	mux.Handle("GET /items/{id}", nethttp.BindMiddleware(itemArgs{})(itemHandler))
+func BindMiddleware(field interface{}, errorWriter ...func(http.ResponseWriter, error, rv.FieldErrors)) func(http.Handler) http.Handler {
+	return rv.BindMiddleware(field, newRequest, errorWriter...)
+}
+
+// BindAllMiddleware is rv.BindAllMiddleware for http.ServeMux, storing
+// the filled container for rv.Bound.
+func BindAllMiddleware(container interface{}, errorWriter ...func(http.ResponseWriter, error, rv.FieldErrors)) func(http.Handler) http.Handler {
+	return rv.BindAllMiddleware(container, newRequest, errorWriter...)
+}
@@ -0,0 +1,207 @@
+//go:build go1.23
+
+package nethttp_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/OwnLocal/rv"
+	. "github.com/OwnLocal/rv/nethttp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Request", func() {
+	var mux *http.ServeMux
+	var req *http.Request
+	var res *httptest.ResponseRecorder
+	var rvReq *Request
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		rvReq = NewRequest(r)
+	}
+
+	mux = http.NewServeMux()
+	mux.HandleFunc("/foo", handler)
+	mux.HandleFunc("GET /foo/{foo}", handler)
+	mux.HandleFunc("GET /foo/{foo}/files/{path...}", handler)
+	mux.HandleFunc("GET /bar/{bar}/{$}", handler)
+
+	BeforeEach(func() {
+		req = httptest.NewRequest("PATCH", "/NOT-SET-SET-ME", nil)
+		res = httptest.NewRecorder()
+	})
+
+	JustBeforeEach(func() {
+		mux.ServeHTTP(res, req.WithContext(context.Background()))
+	})
+
+	Context("With a valid URL querystring", func() {
+		Describe("QueryArgs", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest("GET", "/foo?a=b&c=d", nil)
+			})
+			It("returns the parsed query args", func() {
+				Expect(rvReq.QueryArgs()).To(Equal(url.Values{"a": []string{"b"}, "c": []string{"d"}}))
+			})
+		})
+	})
+
+	Context("With no PathParams", func() {
+		Describe("PathArgs", func() {
+			It("returns an empty map[string]string", func() {
+				Expect(rvReq.PathArgs()).To(Equal(map[string]string(nil)))
+			})
+		})
+	})
+
+	Context("With PathParams", func() {
+		BeforeEach(func() {
+			req = httptest.NewRequest("GET", "/foo/bar", nil)
+		})
+
+		Describe("PathArgs", func() {
+			It("returns the specified params", func() {
+				Expect(rvReq.PathArgs()).To(Equal(map[string]string{"foo": "bar"}))
+			})
+		})
+	})
+
+	Context("With a remainder wildcard", func() {
+		BeforeEach(func() {
+			req = httptest.NewRequest("GET", "/foo/bar/files/a/b.txt", nil)
+		})
+
+		Describe("PathArgs", func() {
+			It("returns the rest of the path", func() {
+				Expect(rvReq.PathArgs()).To(Equal(map[string]string{"foo": "bar", "path": "a/b.txt"}))
+			})
+		})
+	})
+
+	Context("With an end anchor", func() {
+		BeforeEach(func() {
+			req = httptest.NewRequest("GET", "/bar/baz/", nil)
+		})
+
+		Describe("PathArgs", func() {
+			It("leaves out the anchor", func() {
+				Expect(rvReq.PathArgs()).To(Equal(map[string]string{"bar": "baz"}))
+			})
+		})
+	})
+
+})
+
+var _ = Describe("BindMiddleware", func() {
+	type itemArgs struct {
+		ID    int    `rv:"path.id"`
+		Color string `rv:"query.color options=red,blue"`
+	}
+	type pageArgs struct {
+		Page int `rv:"query.page default=1"`
+	}
+
+	var mux *http.ServeMux
+	var res *httptest.ResponseRecorder
+	var bound itemArgs
+	var page pageArgs
+	var ok bool
+
+	BeforeEach(func() {
+		bound, page, ok = itemArgs{}, pageArgs{}, false
+		res = httptest.NewRecorder()
+
+		mux = http.NewServeMux()
+		mux.Handle("GET /items/{id}", BindMiddleware(itemArgs{})(BindMiddleware(pageArgs{})(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				bound, ok = rv.Bound[itemArgs](r)
+				page, _ = rv.Bound[pageArgs](r)
+			}))))
+	})
+
+	It("binds the struct and passes it on in the request context", func() {
+		mux.ServeHTTP(res, httptest.NewRequest("GET", "/items/42?color=red&page=3", nil))
+		Expect(res.Code).To(Equal(http.StatusOK))
+		Expect(ok).To(BeTrue())
+		Expect(bound).To(Equal(itemArgs{ID: 42, Color: "red"}))
+		Expect(page).To(Equal(pageArgs{Page: 3}))
+	})
+
+	It("writes the field errors in declaration order instead of calling the handler", func() {
+		mux.ServeHTTP(res, httptest.NewRequest("GET", "/items/x?color=green", nil))
+		Expect(res.Code).To(Equal(http.StatusBadRequest))
+		Expect(ok).To(BeFalse())
+		Expect(res.Body.String()).To(Equal(
+			"ID strconv.ParseInt: parsing \"x\": invalid syntax\n" +
+				"Color Expected one of []string{\"blue\", \"red\"}, got \"green\"\n"))
+	})
+
+	It("uses the given error writer", func() {
+		var fieldErrs rv.FieldErrors
+		handler := BindMiddleware(itemArgs{}, func(w http.ResponseWriter, err error, errs rv.FieldErrors) {
+			fieldErrs = errs
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		})(http.NotFoundHandler())
+		mux.Handle("GET /other/{id}", handler)
+
+		mux.ServeHTTP(res, httptest.NewRequest("GET", "/other/x", nil))
+		Expect(res.Code).To(Equal(http.StatusUnprocessableEntity))
+		Expect(fieldErrs).NotTo(BeEmpty())
+		Expect(fieldErrs[0].Name).To(Equal("ID"))
+	})
+
+	It("returns nothing for structs which weren't bound", func() {
+		_, ok := rv.Bound[itemArgs](httptest.NewRequest("GET", "/", nil))
+		Expect(ok).To(BeFalse())
+	})
+})
+
+var _ = Describe("BindAllMiddleware", func() {
+	type pathParams struct {
+		ID int `rv:"path.id"`
+	}
+	type queryParams struct {
+		Page int `rv:"query.page default=1 range=1,100"`
+	}
+	type itemContext struct {
+		Path  pathParams
+		Query queryParams
+	}
+
+	var mux *http.ServeMux
+	var res *httptest.ResponseRecorder
+	var bound itemContext
+	var ok bool
+
+	BeforeEach(func() {
+		bound, ok = itemContext{}, false
+		res = httptest.NewRecorder()
+
+		mux = http.NewServeMux()
+		mux.Handle("GET /items/{id}", BindAllMiddleware(itemContext{})(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				bound, ok = rv.Bound[itemContext](r)
+			})))
+	})
+
+	It("binds every struct and passes the container on in the request context", func() {
+		mux.ServeHTTP(res, httptest.NewRequest("GET", "/items/42?page=3", nil))
+		Expect(res.Code).To(Equal(http.StatusOK))
+		Expect(ok).To(BeTrue())
+		Expect(bound).To(Equal(itemContext{Path: pathParams{ID: 42}, Query: queryParams{Page: 3}}))
+	})
+
+	It("writes the errors of every struct at once", func() {
+		mux.ServeHTTP(res, httptest.NewRequest("GET", "/items/x?page=0", nil))
+		Expect(res.Code).To(Equal(http.StatusBadRequest))
+		Expect(ok).To(BeFalse())
+		Expect(res.Body.String()).To(Equal(
+			"Path.ID strconv.ParseInt: parsing \"x\": invalid syntax\n" +
+				"Query.Page 0 not in range 1, 100\n"))
+	})
+})
@@ -0,0 +1,168 @@
+package rv
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// NumberFormat controls which ways of writing numbers TypeHandler
+// accepts in strings, such as query and form values. The zero value
+// accepts decimal numbers only, with or without leading zeros, and
+// rejects NaN and infinite floats. JSON numbers always follow the JSON
+// grammar, so NumberFormat doesn't affect them.
+//
+// A RequestHandler's NumberFormat is set with WithNumberFormat and can
+// be changed for a field with the tag options "bases=hex,octal,binary",
+// "leadingzeros=false" and "nonfinite=true".
+type NumberFormat struct {
+	// Hex, Octal and Binary allow integers with 0x, 0o and 0b prefixes
+	Hex, Octal, Binary bool
+	// NoLeadingZeros rejects numbers like "010"
+	NoLeadingZeros bool
+	// NonFinite allows "NaN", "Inf" and "-Inf" for float fields
+	NonFinite bool
+}
+
+// WithNumberFormat sets the NumberFormat used for every field of the
+// RequestHandler which doesn't set its own with tag options.
+func WithNumberFormat(format NumberFormat) Option {
+	return func(h *RequestHandler) error {
+		h.numbers = format
+		return nil
+	}
+}
+
+// numberOptions are the tag options which change a field's NumberFormat
+var numberOptions = map[string]struct{}{"bases": y, "leadingzeros": y, "nonfinite": y}
+
+// withTagOptions returns the format changed by any of numberOptions in
+// a field's tag options.
+func (nf NumberFormat) withTagOptions(opts map[string][]string) (NumberFormat, error) {
+	if args, ok := opts["bases"]; ok {
+		nf.Hex, nf.Octal, nf.Binary = false, false, false
+		for _, arg := range args {
+			switch arg {
+			case "hex":
+				nf.Hex = true
+			case "octal":
+				nf.Octal = true
+			case "binary":
+				nf.Binary = true
+			case "decimal":
+				// always allowed
+			default:
+				return nf, fmt.Errorf("Expected bases from hex, octal, binary and decimal, got '%s'", arg)
+			}
+		}
+	}
+	if args, ok := opts["leadingzeros"]; ok {
+		allowed, err := strconv.ParseBool(args[0])
+		if err != nil || len(args) != 1 {
+			return nf, fmt.Errorf("invalid value for leadingzeros: %#v", args)
+		}
+		nf.NoLeadingZeros = !allowed
+	}
+	if args, ok := opts["nonfinite"]; ok {
+		allowed, err := strconv.ParseBool(args[0])
+		if err != nil || len(args) != 1 {
+			return nf, fmt.Errorf("invalid value for nonfinite: %#v", args)
+		}
+		nf.NonFinite = allowed
+	}
+	return nf, nil
+}
+
+// isNumberType reports whether a field's type, or its element or map
+// key type, is a number, given its "type" tag option.
+func isNumberType(types []string) bool {
+	for _, t := range types {
+		if strings.HasPrefix(t, "int") || strings.HasPrefix(t, "uint") || strings.HasPrefix(t, "float") {
+			return true
+		}
+	}
+	return false
+}
+
+var integerPrefixes = []struct {
+	prefix, name string
+	base         int
+}{
+	{"0x", "hexadecimal", 16},
+	{"0o", "octal", 8},
+	{"0b", "binary", 2},
+}
+
+func (nf NumberFormat) allowsBase(base int) bool {
+	switch base {
+	case 16:
+		return nf.Hex
+	case 8:
+		return nf.Octal
+	case 2:
+		return nf.Binary
+	}
+	return true
+}
+
+// splitInteger splits the sign and any base prefix from s, returning
+// the digits to parse in base.
+func (nf NumberFormat) splitInteger(s string) (sign, digits string, base int, err error) {
+	digits, base = s, 10
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		sign, digits = digits[:1], digits[1:]
+	}
+
+	lower := strings.ToLower(digits)
+	for _, p := range integerPrefixes {
+		if strings.HasPrefix(lower, p.prefix) {
+			if !nf.allowsBase(p.base) {
+				return "", "", 0, fmt.Errorf("%s numbers are not allowed, got '%s'", p.name, s)
+			}
+			return sign, digits[len(p.prefix):], p.base, nil
+		}
+	}
+
+	if nf.NoLeadingZeros && len(digits) > 1 && digits[0] == '0' && digits[1] >= '0' && digits[1] <= '9' {
+		return "", "", 0, fmt.Errorf("leading zeros are not allowed, got '%s'", s)
+	}
+	return sign, digits, base, nil
+}
+
+func (nf NumberFormat) parseInt(s string) (int64, error) {
+	sign, digits, base, err := nf.splitInteger(s)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(sign+digits, base, 64)
+}
+
+func (nf NumberFormat) parseUint(s string) (uint64, error) {
+	sign, digits, base, err := nf.splitInteger(s)
+	if err != nil {
+		return 0, err
+	}
+	if sign == "-" {
+		return 0, fmt.Errorf("int %s can't be represented as an unsigned number", s)
+	}
+	return strconv.ParseUint(digits, base, 64)
+}
+
+func (nf NumberFormat) parseFloat(s string, bitSize int) (float64, error) {
+	if _, _, _, err := nf.splitInteger(s); err != nil {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(s, bitSize)
+	if err != nil {
+		return 0, err
+	}
+	return f, nf.checkFinite(f)
+}
+
+func (nf NumberFormat) checkFinite(f float64) error {
+	if !nf.NonFinite && (math.IsNaN(f) || math.IsInf(f, 0)) {
+		return fmt.Errorf("float %v is not allowed", f)
+	}
+	return nil
+}
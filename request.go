@@ -4,11 +4,20 @@ package rv
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
 	"net/url"
 	"strings"
 )
 
+// DefaultMaxMemory is the number of bytes of a multipart body's file
+// parts kept in memory when a Request doesn't set its own limit. The
+// remainder is stored in temporary files.
+const DefaultMaxMemory = 32 << 20
+
 // Request is the interface to implement to allow rv to read values
 // from a HTTP request.
 type Request interface {
@@ -22,12 +31,70 @@ type Request interface {
 	BodyForm() (url.Values, error)
 }
 
+// MultipartRequest is implemented by Requests which can parse a
+// multipart/form-data body, for the "file" source.
+type MultipartRequest interface {
+	Request
+	// BodyMultipart reads the request body and parses it as
+	// multipart/form-data, returning nil if the body is not multipart
+	BodyMultipart() (*multipart.Form, error)
+}
+
+// HeaderRequest is implemented by Requests which can provide the
+// request headers, for the "header" source.
+type HeaderRequest interface {
+	Request
+	// HeaderArgs returns the request headers
+	HeaderArgs() (http.Header, error)
+}
+
+// CookieRequest is implemented by Requests which can provide the
+// cookies sent with the request, for the "cookie" source.
+type CookieRequest interface {
+	Request
+	// CookieArgs returns the cookies sent with the request
+	CookieArgs() ([]*http.Cookie, error)
+}
+
+// requestHeader returns the headers of req, or an error if it isn't a
+// HeaderRequest.
+func requestHeader(req Request) (http.Header, error) {
+	if hr, ok := req.(HeaderRequest); ok {
+		return hr.HeaderArgs()
+	}
+	return nil, fmt.Errorf("%T doesn't provide headers", req)
+}
+
+// requestMultipart returns the multipart body of req, or an error if it
+// isn't a MultipartRequest.
+func requestMultipart(req Request) (*multipart.Form, error) {
+	if mr, ok := req.(MultipartRequest); ok {
+		return mr.BodyMultipart()
+	}
+	return nil, fmt.Errorf("%T doesn't provide multipart bodies", req)
+}
+
+// requestCookies returns the cookies of req, or an error if it isn't a
+// CookieRequest.
+func requestCookies(req Request) ([]*http.Cookie, error) {
+	if cr, ok := req.(CookieRequest); ok {
+		return cr.CookieArgs()
+	}
+	return nil, fmt.Errorf("%T doesn't provide cookies", req)
+}
+
 // BasicRequest implements the Request interface and can be used for
-// testing or parsing requests from unsupported request types.
+// testing or parsing requests from unsupported request types. A
+// multipart body can be used by setting a multipart/form-data
+// Content-Type, including the boundary, in Header.
 type BasicRequest struct {
-	Query string
-	Path  map[string]string
-	Body  string
+	Query     string
+	Path      map[string]string
+	Body      string
+	Header    http.Header
+	MaxMemory int64
+
+	multipartForm *multipart.Form
 }
 
 // QueryArgs parses the Query field
@@ -45,18 +112,64 @@ func (r *BasicRequest) BodyJSON() (map[string]interface{}, error) {
 	return ParseJSONBody(strings.NewReader(r.Body))
 }
 
-// BodyForm parses the Body string as a form
+// BodyForm parses the Body string as a form, using the text parts if
+// the body is multipart
 func (r *BasicRequest) BodyForm() (url.Values, error) {
+	if IsMultipart(r.Header.Get("Content-Type")) {
+		form, err := r.BodyMultipart()
+		if form == nil {
+			return nil, err
+		}
+		return url.Values(form.Value), err
+	}
 	return url.ParseQuery(r.Body)
 }
 
-// ParseJSONBody attempts to parse a JSON body from the provided io.Reader
+// BodyMultipart parses the Body string as multipart/form-data. The
+// parsed form is kept so it can be requested more than once; call its
+// RemoveAll method when done with it to remove any temporary files.
+func (r *BasicRequest) BodyMultipart() (*multipart.Form, error) {
+	if r.multipartForm != nil {
+		return r.multipartForm, nil
+	}
+	form, err := ParseMultipartBody(strings.NewReader(r.Body), r.Header.Get("Content-Type"), r.MaxMemory)
+	r.multipartForm = form
+	return form, err
+}
+
+// HeaderArgs returns the Header field value
+func (r *BasicRequest) HeaderArgs() (http.Header, error) {
+	return r.Header, nil
+}
+
+// CookieArgs parses any Cookie headers in the Header field
+func (r *BasicRequest) CookieArgs() ([]*http.Cookie, error) {
+	return (&http.Request{Header: r.Header}).Cookies(), nil
+}
+
+// RawBody returns the Body string, so a CachedRequest can decode it
+// itself
+func (r *BasicRequest) RawBody() (io.Reader, error) {
+	return strings.NewReader(r.Body), nil
+}
+
+// ParseJSONBody attempts to parse a JSON body from the provided
+// io.Reader.
 func ParseJSONBody(body io.Reader) (map[string]interface{}, error) {
+	return decodeJSONBody(body, false)
+}
+
+// decodeJSONBody parses a JSON body, decoding numbers as json.Number
+// rather than float64 if useNumber is set.
+func decodeJSONBody(body io.Reader, useNumber bool) (map[string]interface{}, error) {
 	if body == nil {
 		return nil, nil
 	}
 
 	decoder := json.NewDecoder(body)
+	if useNumber {
+		decoder.UseNumber()
+	}
 
 	parsed := make(map[string]interface{})
 
@@ -68,3 +181,34 @@ func ParseJSONBody(body io.Reader) (map[string]interface{}, error) {
 
 	return parsed, nil
 }
+
+// IsMultipart reports whether the provided Content-Type header value
+// is multipart/form-data.
+func IsMultipart(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "multipart/form-data"
+}
+
+// ParseMultipartBody attempts to parse a multipart/form-data body from
+// the provided io.Reader, keeping up to maxMemory bytes of file parts
+// in memory (DefaultMaxMemory if maxMemory is not positive). Larger
+// files are written to temporary files, which the caller must remove
+// with the form's RemoveAll method. It returns nil if contentType is
+// not multipart/form-data.
+func ParseMultipartBody(body io.Reader, contentType string, maxMemory int64) (*multipart.Form, error) {
+	if body == nil || !IsMultipart(contentType) {
+		return nil, nil
+	}
+
+	_, params, _ := mime.ParseMediaType(contentType)
+	boundary, ok := params["boundary"]
+	if !ok {
+		return nil, http.ErrMissingBoundary
+	}
+
+	if maxMemory <= 0 {
+		maxMemory = DefaultMaxMemory
+	}
+
+	return multipart.NewReader(body, boundary).ReadForm(maxMemory)
+}
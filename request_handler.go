@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
 )
 
@@ -14,12 +16,104 @@ var handlerMap = map[string]FieldHandlerCreator{
 	"range":    NewRangeHandler,
 	"options":  NewOptionsHandler,
 	"required": NewRequiredHandler,
+
+	"maxsize":      NewMaxSizeHandler,
+	"contenttypes": NewContentTypesHandler,
+}
+
+var (
+	customHandlers     = map[string]FieldHandlerCreator{}
+	customHandlersLock sync.RWMutex
+)
+
+// RegisterHandler makes a custom handler available in the rv tags of
+// all RequestHandlers created afterwards, so that "sku=true" in a tag
+// calls creator with []string{"true"}. It returns an error if name is
+// used by a built-in handler, has already been registered or can't be
+// used in a tag. See PrecidenceFieldHandler for when custom handlers
+// run.
+func RegisterHandler(name string, creator FieldHandlerCreator) error {
+	if err := checkHandlerName(name); err != nil {
+		return err
+	}
+
+	customHandlersLock.Lock()
+	defer customHandlersLock.Unlock()
+	if _, ok := customHandlers[name]; ok {
+		return fmt.Errorf("handler %#v is already registered", name)
+	}
+	customHandlers[name] = creator
+	return nil
+}
+
+// WithHandler makes a custom handler available only in the tags of the
+// RequestHandler being created (and the nested structs it binds). It
+// may replace a handler registered with RegisterHandler, but not a
+// built-in one.
+func WithHandler(name string, creator FieldHandlerCreator) Option {
+	return func(h *RequestHandler) error {
+		if err := checkHandlerName(name); err != nil {
+			return err
+		}
+		if h.handlers == nil {
+			h.handlers = map[string]FieldHandlerCreator{}
+		}
+		h.handlers[name] = creator
+		return nil
+	}
+}
+
+func checkHandlerName(name string) error {
+	if _, ok := handlerMap[name]; ok {
+		return fmt.Errorf("handler %#v conflicts with a built-in handler", name)
+	}
+	if _, ok := reservedKeys[name]; ok {
+		return fmt.Errorf("handler %#v conflicts with a reserved tag key", name)
+	}
+	if name == "" || strings.ContainsAny(name, " =,") || strings.HasPrefix(name, keyPrefix) {
+		return fmt.Errorf("invalid handler name %#v", name)
+	}
+	return nil
+}
+
+// Option configures a RequestHandler created by NewRequestHandler.
+type Option func(*RequestHandler) error
+
+// WithFailFast makes Run stop at the first field with errors, rather
+// than validating every field.
+func WithFailFast(failFast bool) Option {
+	return func(h *RequestHandler) error {
+		h.failFast = failFast
+		return nil
+	}
+}
+
+// WithMaxBodySize limits how much of a request body the RequestHandler
+// buffers, see CachedRequest.
+func WithMaxBodySize(size int64) Option {
+	return func(h *RequestHandler) error {
+		if size <= 0 {
+			return fmt.Errorf("max body size must be positive, got %d", size)
+		}
+		h.maxBodySize = size
+		return nil
+	}
 }
 
 // RequestHandler extracts and validates values from a request based on "rv" tags on the struct fields.
 type RequestHandler struct {
 	Fields      map[string]FieldHandlers
 	requestType reflect.Type
+	// order holds the names in Fields in struct declaration order
+	order []string
+
+	options     []Option
+	handlers    map[string]FieldHandlerCreator
+	maxBodySize int64
+	numbers     NumberFormat
+	strict      bool
+	failFast    bool
+	naming      NamingStrategy
 
 	indexCache     map[reflect.Type]int
 	indexCacheLock sync.Mutex
@@ -28,76 +122,405 @@ type RequestHandler struct {
 // NewRequestHandler builds a RequestHandler which will extract and
 // validate values from a request based on the "rv" tags on the struct
 // fields.
-func NewRequestHandler(requestStruct interface{}) (*RequestHandler, error) {
-	tags, err := extractTags(requestStruct)
-	if err != nil {
+func NewRequestHandler(requestStruct interface{}, options ...Option) (*RequestHandler, error) {
+	return newRequestHandler(requestStruct, options, map[reflect.Type]*RequestHandler{})
+}
+
+// newRequestHandler does the work of NewRequestHandler, reusing the
+// handlers in building for nested struct types which are already being
+// built so recursive types don't recurse forever. Nested structs get
+// the same options. Problems with the tags are returned together as
+// TagErrors.
+func newRequestHandler(requestStruct interface{}, options []Option, building map[reflect.Type]*RequestHandler) (*RequestHandler, error) {
+	reqType := reflect.TypeOf(requestStruct)
+	requestHandler := &RequestHandler{
+		requestType: reqType,
+		options:     options,
+		indexCache:  make(map[reflect.Type]int)}
+	for _, option := range options {
+		if err := option(requestHandler); err != nil {
+			return nil, err
+		}
+	}
+
+	tags, err := extractTags(requestStruct, requestHandler.naming)
+	syntaxErrs, _ := err.(TagErrors)
+	if err != nil && syntaxErrs == nil {
 		return nil, err
 	}
+	building[reqType] = requestHandler
 
-	requestHandler := RequestHandler{
-		requestType: reflect.TypeOf(requestStruct),
-		indexCache:  make(map[reflect.Type]int)}
+	// Errors are collected field by field so they come out in
+	// declaration order
+	fieldSyntaxErrs := map[string]TagErrors{}
+	for _, te := range syntaxErrs {
+		fieldSyntaxErrs[te.Field] = append(fieldSyntaxErrs[te.Field], te)
+	}
+	var tagErrs TagErrors
 
 	handlers := map[string]FieldHandlers{}
-	for field, opts := range tags {
-		fieldHandlers := FieldHandlers{}
-		isList := false
-		var listHandler ListHandler
+	for i := 0; i < reqType.NumField(); i++ {
+		field := reqType.Field(i)
+		tagErrs = append(tagErrs, fieldSyntaxErrs[field.Name]...)
+		if embedded := embeddedTags(field, map[reflect.Type]bool{}); embedded != "" {
+			// Only the struct's own fields are bound
+			tagErrs = append(tagErrs, &TagError{Field: field.Name, Err: fmt.Errorf("rv tags on embedded struct %s aren't bound", embedded)})
+			continue
+		}
+		opts, ok := tags[field.Name]
+		if !ok {
+			continue
+		}
+
+		fieldHandlers, errs := requestHandler.newFieldHandlers(field, opts, building)
+		if len(errs) == 0 {
+			errs = checkFieldHandlers(fieldHandlers, opts["type"])
+		}
+		for _, err := range errs {
+			tagErrs = append(tagErrs, newTagErrors(field.Name, err)...)
+		}
+		handlers[field.Name] = fieldHandlers
+		requestHandler.order = append(requestHandler.order, field.Name)
+	}
+	if len(tagErrs) > 0 {
+		return nil, tagErrs
+	}
+
+	requestHandler.Fields = handlers
+	return requestHandler, nil
+}
 
-		for opt, args := range opts {
-			var err error
-			if opt == "type" && args[0] == "slice" {
-				isList = true
-				listHandler = ListHandler{}
-				listHandler.SubHandlers, err = addRegularHandler(FieldHandlers{}, "type", args[1:2])
-			} else {
-				fieldHandlers, err = addRegularHandler(fieldHandlers, opt, args)
+// newFieldHandlers builds the handlers for a field from its tag
+// options, returning every problem with the options.
+func (h *RequestHandler) newFieldHandlers(structField reflect.StructField, opts map[string][]string, building map[reflect.Type]*RequestHandler) (FieldHandlers, []error) {
+	var errs []error
+	fieldHandlers := FieldHandlers{}
+	isList, isMap := false, false
+	hasSep, hasRepeated, repeated := false, false, true
+	var listHandler ListHandler
+	var mapHandler MapHandler
+
+	fieldType := derefType(structField.Type)
+
+	numbers, err := h.numbers.withTagOptions(opts)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	strict := h.strict
+
+	names := make([]string, 0, len(opts))
+	for opt := range opts {
+		names = append(names, opt)
+	}
+	sort.Strings(names)
+
+	for _, opt := range names {
+		args := opts[opt]
+		var err error
+		var handler, keyHandler FieldHandler
+		if opt == "type" && args[0] == "slice" && !isCustomType(fieldType) {
+			isList = true
+			handler, err = h.newTypeHandler(fieldType.Elem(), args[1], numbers, building)
+			listHandler.SubHandlers = FieldHandlers{handler}
+		} else if opt == "type" && args[0] == "map" && !isCustomType(fieldType) {
+			isMap = true
+			keyHandler, err = h.newTypeHandler(fieldType.Key(), args[1], numbers, building)
+			if err == nil {
+				handler, err = h.newTypeHandler(fieldType.Elem(), args[2], numbers, building)
+			}
+			mapHandler.KeyHandlers = append(mapHandler.KeyHandlers, keyHandler)
+			mapHandler.ValueHandlers = FieldHandlers{handler}
+		} else if opt == "type" {
+			handler, err = h.newTypeHandler(fieldType, args[0], numbers, building)
+			fieldHandlers = append(fieldHandlers, handler)
+		} else if opt == "sep" {
+			hasSep = true
+			listHandler.Separator, listHandler.NoSplit, err = newListSeparator(args)
+		} else if opt == "repeated" {
+			if len(args) != 1 {
+				err = fmt.Errorf("need a single argument for repeated, got %#v", args)
+			} else if repeated, err = strconv.ParseBool(args[0]); err != nil {
+				err = fmt.Errorf("invalid value for repeated: %#v", args[0])
 			}
-			if err != nil {
-				return nil, err
+			hasRepeated = true
+		} else if opt == "strict" {
+			strict, err = strictOption(args)
+		} else if _, ok := numberOptions[opt]; ok {
+			if !isNumberType(opts["type"]) {
+				err = fmt.Errorf("%s can only be used on number fields", opt)
 			}
+		} else if strings.HasPrefix(opt, keyPrefix) {
+			mapHandler.KeyHandlers, err = h.addRegularHandler(mapHandler.KeyHandlers, strings.TrimPrefix(opt, keyPrefix), args)
+		} else {
+			fieldHandlers, err = h.addRegularHandler(fieldHandlers, opt, args)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
 
+	if len(mapHandler.KeyHandlers) > 0 && !isMap {
+		errs = append(errs, fmt.Errorf("%s handlers can only be used on map fields", keyPrefix))
+	}
+	if hasSep && !isList {
+		errs = append(errs, fmt.Errorf("sep can only be used on slice fields"))
+	}
+	if hasRepeated && (isList || isMap) {
+		errs = append(errs, fmt.Errorf("repeated can't be used on slice or map fields"))
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	if !repeated {
+		fieldHandlers = rejectRepeated(fieldHandlers)
+	}
+	if strict && strings.HasPrefix(fieldSource(fieldHandlers), "json.") {
+		if handler := newStrictTypeHandler(fieldType, opts["type"]); handler != nil {
+			fieldHandlers = append(fieldHandlers, handler)
 		}
-		sort.Stable(fieldHandlers)
-		if isList {
-			fieldHandlers = addListHandler(fieldHandlers, listHandler)
+	}
+	if structField.Type.Kind() == reflect.Ptr && structField.Type != fileHeaderType && !isList && !isMap {
+		fieldHandlers = append(fieldHandlers, newAbsentHandler(fieldHandlers))
+	}
+	sort.Stable(fieldHandlers)
+	if isList {
+		for _, handler := range fieldHandlers {
+			if source, ok := handler.(SourceFieldHandler); ok && source.Source == HEADER {
+				listHandler.TrimSpace = true
+			}
 		}
-		handlers[field] = fieldHandlers
+		fieldHandlers = addListHandler(fieldHandlers, listHandler)
+	} else if isMap {
+		sort.Stable(mapHandler.KeyHandlers)
+		fieldHandlers = addMapHandler(fieldHandlers, mapHandler)
 	}
-	requestHandler.Fields = handlers
-	return &requestHandler, nil
+	return fieldHandlers, nil
+}
+
+// FieldError is a field which had errors, named by its path.
+type FieldError struct {
+	Name string
+	Field
+}
+
+// FieldErrors lists the fields with errors from RunOrdered in the order
+// they were processed: struct declaration order, with the nested
+// fields of a field following it in path order.
+type FieldErrors []FieldError
+
+// Map returns the fields keyed by name, as Run returns them.
+func (e FieldErrors) Map() map[string]Field {
+	fields := make(map[string]Field, len(e))
+	for _, fe := range e {
+		fields[fe.Name] = fe.Field
+	}
+	return fields
 }
 
 // Run fills the provided struct with data from the request, as
-// specified in the "rv" tags on the struct fields.
+// specified in the "rv" tags on the struct fields. The request is
+// wrapped in a CachedRequest so each source is only parsed once.
 func (h *RequestHandler) Run(req Request, requestStruct interface{}) (argErr error, fieldErrors map[string]Field) {
+	argErr, errs := h.RunOrdered(req, requestStruct)
+	if argErr != nil {
+		return argErr, nil
+	}
+	return nil, errs.Map()
+}
+
+// RunOrdered is like Run, but processes the fields in struct
+// declaration order and returns the fields with errors in that order.
+func (h *RequestHandler) RunOrdered(req Request, requestStruct interface{}) (argErr error, fieldErrors FieldErrors) {
 	val := reflect.ValueOf(requestStruct)
 	if val.Type().Kind() != reflect.Ptr || val.Type().Elem() != h.requestType {
 		return fmt.Errorf("Expected *%v, got %v", h.requestType, val.Type()), nil
 	}
 	val = val.Elem()
+	req = NewCachedRequest(req, h.maxBodySize)
 
-	fieldErrors = make(map[string]Field)
-	for name, handlers := range h.Fields {
+	for _, name := range h.fieldNames() {
+		handlers := h.Fields[name]
 		field := Field{}
-		for _, handler := range handlers {
-			handler.Run(req, &field)
+		handlers.Run(req, &field)
+		if len(field.Errors) == 0 && len(field.Fields) == 0 {
+			if field.Value != nil {
+				if err := setField(val.FieldByName(name), field.Value); err != nil {
+					field.Errors = append(field.Errors, wrapValidationError("type", field.Value, nil, err))
+				}
+			} else if field.Null {
+				clearField(val.FieldByName(name))
+			}
 		}
+		source := fieldSource(handlers)
 		if len(field.Errors) > 0 {
-			fieldErrors[name] = field
-		} else if field.Value != nil {
-			val.FieldByName(name).Set(reflect.ValueOf(field.Value))
+			annotateErrors(field.Errors, name, source)
+			fieldErrors = append(fieldErrors, FieldError{Name: name, Field: field})
+		}
+		for _, path := range sortedPaths(field.Fields) {
+			nested := field.Fields[path]
+			path = joinPath(name, path)
+			if strings.HasPrefix(source, "json.") {
+				if parent, err := jsonPath(strings.TrimPrefix(source, "json.")); err == nil {
+					nestSources(nested.Errors, parent)
+				}
+			}
+			annotateErrors(nested.Errors, path, source)
+			fieldErrors = append(fieldErrors, FieldError{Name: path, Field: nested})
+		}
+		if h.failFast && len(fieldErrors) > 0 {
+			break
 		}
 	}
 
 	return nil, fieldErrors
 }
 
+// Fill is like Run, but returns a single error: either the argument
+// error, or ValidationErrors holding the errors for every field.
+func (h *RequestHandler) Fill(req Request, requestStruct interface{}) error {
+	argErr, fieldErrors := h.RunOrdered(req, requestStruct)
+	if argErr != nil {
+		return argErr
+	}
+	if len(fieldErrors) > 0 {
+		return newValidationErrors(fieldErrors)
+	}
+	return nil
+}
+
+// fieldNames gives the names in Fields in the order they are processed,
+// falling back to sorted order if Fields was changed by hand.
+func (h *RequestHandler) fieldNames() []string {
+	if len(h.order) == len(h.Fields) {
+		return h.order
+	}
+	names := make([]string, 0, len(h.Fields))
+	for name := range h.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortedPaths gives the paths of nested fields, with list indexes in
+// numeric order.
+func sortedPaths(fields map[string]Field) []string {
+	paths := make([]string, 0, len(fields))
+	for path := range fields {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool { return pathLess(paths[i], paths[j]) })
+	return paths
+}
+
+// pathLess compares paths like strings, except that runs of digits are
+// compared as numbers so "[2]" comes before "[10]".
+func pathLess(a, b string) bool {
+	for a != "" && b != "" {
+		da, db := leadingDigits(a), leadingDigits(b)
+		if da != "" && db != "" {
+			if len(da) != len(db) {
+				return len(da) < len(db)
+			}
+			if da != db {
+				return da < db
+			}
+			a, b = a[len(da):], b[len(db):]
+			continue
+		}
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+func leadingDigits(s string) string {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return s[:i]
+}
+
+// fieldSource gives the source from a field's rv tag.
+func fieldSource(handlers FieldHandlers) string {
+	for _, handler := range handlers {
+		if source, ok := handler.(SourceFieldHandler); ok {
+			return strings.ToLower(source.Source.String()) + "." + source.Field
+		}
+	}
+	return ""
+}
+
+// annotateErrors records the field path, and the source if a nested
+// handler hasn't already, on any ValidationErrors. They are replaced by
+// copies so errors a handler shares between requests aren't changed.
+func annotateErrors(errs []error, path, source string) {
+	for i, err := range errs {
+		if ve, ok := err.(*ValidationError); ok {
+			annotated := *ve
+			annotated.Field = path
+			if annotated.Source == "" {
+				annotated.Source = source
+			}
+			errs[i] = &annotated
+		}
+	}
+}
+
+// nestSources makes the JSON sources of the ValidationErrors from a
+// nested object, which are relative to that object, relative to its
+// parent instead by prefixing the JSON path segments of the object,
+// replacing them by copies like annotateErrors.
+func nestSources(errs []error, parent []string) {
+	for i, err := range errs {
+		ve, ok := err.(*ValidationError)
+		if !ok || !strings.HasPrefix(ve.Source, "json.") {
+			continue
+		}
+		path, err := jsonPath(strings.TrimPrefix(ve.Source, "json."))
+		if err != nil {
+			continue
+		}
+		nested := *ve
+		nested.Source = "json." + formatJSONPath(append(append([]string{}, parent...), path...))
+		errs[i] = &nested
+	}
+}
+
+// formatJSONPath writes a path as jsonPath reads it: dot-separated, or
+// as a JSON Pointer if a segment contains a dot or starts with a slash.
+func formatJSONPath(path []string) string {
+	for _, segment := range path {
+		if segment == "" || strings.Contains(segment, ".") || strings.HasPrefix(path[0], "/") {
+			escaped := make([]string, len(path))
+			for i, segment := range path {
+				escaped[i] = strings.Replace(strings.Replace(segment, "~", "~0", -1), "/", "~1", -1)
+			}
+			return "/" + strings.Join(escaped, "/")
+		}
+	}
+	return strings.Join(path, ".")
+}
+
 // Bind searches the container for a field matching the
 // RequestHandler's field type, then fills it by calling
 // RequestHandler.Run with the specified Request and the matching
 // field.
 func (h *RequestHandler) Bind(req Request, container interface{}) (argErr error, fieldErrors map[string]Field) {
+	argErr, errs := h.BindOrdered(req, container)
+	if argErr != nil {
+		return argErr, nil
+	}
+	return nil, errs.Map()
+}
+
+// BindOrdered is like Bind, but fills the field with RunOrdered and
+// returns the fields with errors in declaration order.
+func (h *RequestHandler) BindOrdered(req Request, container interface{}) (argErr error, fieldErrors FieldErrors) {
 	val := reflect.ValueOf(container)
 	if val.Kind() != reflect.Ptr {
 		return fmt.Errorf("Expected pointer to struct, got %T", container), nil
@@ -113,7 +536,7 @@ func (h *RequestHandler) Bind(req Request, container interface{}) (argErr error,
 		return err, nil
 	}
 
-	return h.Run(req, val.Field(i).Addr().Interface())
+	return h.RunOrdered(req, val.Field(i).Addr().Interface())
 }
 
 func (h *RequestHandler) fieldIndex(container reflect.Type) (int, error) {
@@ -141,8 +564,138 @@ func (h *RequestHandler) fieldIndex(container reflect.Type) (int, error) {
 	return 0, fmt.Errorf("No %v field found in provided %v", h.requestType, container)
 }
 
-func addRegularHandler(fieldHandlers FieldHandlers, opt string, args []string) (FieldHandlers, error) {
+// setField stores value in the struct field fv, converting it to the
+// field's type if needed.
+func setField(fv reflect.Value, value interface{}) error {
+	v, ok := convertValue(reflect.ValueOf(value), fv.Type())
+	if !ok {
+		return fmt.Errorf("can't store %T in %v", value, fv.Type())
+	}
+	fv.Set(v)
+	return nil
+}
+
+// clearField sets pointer, slice and map fields to nil, leaving other
+// fields alone.
+func clearField(fv reflect.Value) {
+	switch fv.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		fv.Set(reflect.Zero(fv.Type()))
+	}
+}
+
+// convertValue converts v to type t, allocating pointers and
+// converting slice elements as needed.
+func convertValue(v reflect.Value, t reflect.Type) (reflect.Value, bool) {
+	switch {
+	case v.Type() == t:
+		return v, true
+
+	case t.Kind() == reflect.Ptr:
+		elem, ok := convertValue(v, t.Elem())
+		if !ok {
+			return v, false
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, true
+
+	case t.Kind() == reflect.Slice && v.Kind() == reflect.Slice:
+		slice := reflect.MakeSlice(t, v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elem := v.Index(i)
+			if elem.Kind() == reflect.Interface {
+				elem = elem.Elem()
+			}
+			if !elem.IsValid() {
+				continue
+			}
+			elem, ok := convertValue(elem, t.Elem())
+			if !ok {
+				return v, false
+			}
+			slice.Index(i).Set(elem)
+		}
+		return slice, true
+
+	case t.Kind() == reflect.Map && v.Kind() == reflect.Map:
+		m := reflect.MakeMapWithSize(t, v.Len())
+		for _, key := range v.MapKeys() {
+			k, ok := convertValue(key, t.Key())
+			elem := v.MapIndex(key)
+			if elem.Kind() == reflect.Interface {
+				elem = elem.Elem()
+			}
+			if !ok || !elem.IsValid() {
+				return v, false
+			}
+			elem, ok = convertValue(elem, t.Elem())
+			if !ok {
+				return v, false
+			}
+			m.SetMapIndex(k, elem)
+		}
+		return m, true
+
+	case v.Type().ConvertibleTo(t) && v.Kind() == t.Kind():
+		return v.Convert(t), true
+	}
+	return v, false
+}
+
+// joinPath adds a nested field path to the name of its parent field.
+func joinPath(name, path string) string {
+	if strings.HasPrefix(path, "[") {
+		return name + path
+	}
+	return name + "." + path
+}
+
+// newTypeHandler picks the handler which converts values to type t,
+// known to TypeHandler by name: a CustomTypeHandler for types which
+// have a Converter or unmarshal themselves, a StructHandler for nested
+// structs, or a TypeHandler.
+func (h *RequestHandler) newTypeHandler(t reflect.Type, name string, numbers NumberFormat, building map[reflect.Type]*RequestHandler) (FieldHandler, error) {
+	t = derefType(t)
+	if isCustomType(t) {
+		return CustomTypeHandler{Type: t}, nil
+	}
+	if nested := structType(t); nested != nil {
+		return h.newStructHandler(nested, building)
+	}
+	handler, err := NewTypeHandler([]string{name})
+	if err != nil {
+		return nil, err
+	}
+	typeHandler := handler.(TypeHandler)
+	typeHandler.Numbers = numbers
+	return typeHandler, nil
+}
+
+func (h *RequestHandler) newStructHandler(t reflect.Type, building map[reflect.Type]*RequestHandler) (FieldHandler, error) {
+	if handler, ok := building[t]; ok {
+		return StructHandler{Handler: handler}, nil
+	}
+	handler, err := newRequestHandler(reflect.Zero(t).Interface(), h.options, building)
+	if err != nil {
+		return nil, err
+	}
+	return StructHandler{Handler: handler}, nil
+}
+
+// addRegularHandler creates the handler for a tag option, looking for
+// built-in handlers first, then ones given to this RequestHandler with
+// WithHandler, then ones registered with RegisterHandler.
+func (h *RequestHandler) addRegularHandler(fieldHandlers FieldHandlers, opt string, args []string) (FieldHandlers, error) {
 	handlerCreator, ok := handlerMap[opt]
+	if !ok {
+		handlerCreator, ok = h.handlers[opt]
+	}
+	if !ok {
+		customHandlersLock.RLock()
+		handlerCreator, ok = customHandlers[opt]
+		customHandlersLock.RUnlock()
+	}
 	if !ok {
 		return fieldHandlers, fmt.Errorf("Invalid handler: %s", opt)
 	}
@@ -156,8 +709,11 @@ func addRegularHandler(fieldHandlers FieldHandlers, opt string, args []string) (
 
 func addListHandler(fieldHandlers FieldHandlers, listHandler ListHandler) (fh FieldHandlers) {
 	for _, handler := range fieldHandlers {
-		switch handler.(type) {
-		case SourceFieldHandler, TypeHandler, DefaultHandler:
+		switch h := handler.(type) {
+		case SourceFieldHandler:
+			h.List = true
+			fh = append(fh, h)
+		case TypeHandler, DefaultHandler, StrictTypeHandler:
 			fh = append(fh, handler)
 		default:
 			listHandler.SubHandlers = append(listHandler.SubHandlers, handler)
@@ -167,9 +723,46 @@ func addListHandler(fieldHandlers FieldHandlers, listHandler ListHandler) (fh Fi
 	return fh
 }
 
+// rejectRepeated makes the field's source reject repeated keys.
+func rejectRepeated(fieldHandlers FieldHandlers) FieldHandlers {
+	for i, handler := range fieldHandlers {
+		if h, ok := handler.(SourceFieldHandler); ok {
+			h.RejectRepeated = true
+			fieldHandlers[i] = h
+		}
+	}
+	return fieldHandlers
+}
+
+func addMapHandler(fieldHandlers FieldHandlers, mapHandler MapHandler) (fh FieldHandlers) {
+	for _, handler := range fieldHandlers {
+		switch h := handler.(type) {
+		case SourceFieldHandler:
+			h.Map = true
+			fh = append(fh, h)
+		case DefaultHandler, StrictTypeHandler:
+			fh = append(fh, handler)
+		default:
+			mapHandler.ValueHandlers = append(mapHandler.ValueHandlers, handler)
+		}
+	}
+	fh = append(fh, mapHandler)
+	return fh
+}
+
 // FieldHandlers is a sortable list of FieldHandlers
 type FieldHandlers []FieldHandler
 
+// Run runs the handlers in order until one of them halts the field.
+func (f FieldHandlers) Run(req Request, field *Field) {
+	for _, handler := range f {
+		if field.Halt {
+			return
+		}
+		handler.Run(req, field)
+	}
+}
+
 func (f FieldHandlers) precidence(i int) int {
 	if fv, ok := f[i].(PrecidenceFieldHandler); ok {
 		return fv.Precidence()
@@ -1,12 +1,81 @@
 package rv_test
 
 import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"reflect"
+	"time"
+
 	"github.com/OwnLocal/rv"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
 
+type color int
+
+func (c *color) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "red":
+		*c = 1
+	case "blue":
+		*c = 2
+	default:
+		return fmt.Errorf("unknown color %q", text)
+	}
+	return nil
+}
+
+type point struct{ X, Y int }
+
+func (p *point) UnmarshalJSON(data []byte) error {
+	var xy []int
+	if err := json.Unmarshal(data, &xy); err != nil || len(xy) != 2 {
+		return fmt.Errorf("expected [x, y], got %s", data)
+	}
+	p.X, p.Y = xy[0], xy[1]
+	return nil
+}
+
+type cents int64
+
+func init() {
+	rv.RegisterConverter(reflect.TypeOf(cents(0)), func(value interface{}) (interface{}, error) {
+		var dollars float64
+		if _, err := fmt.Sscanf(fmt.Sprint(value), "$%f", &dollars); err != nil {
+			return nil, fmt.Errorf("expected $ amount, got %v", value)
+		}
+		return cents(dollars * 100), nil
+	})
+}
+
+// skuHandler checks values look like "ABC-123" when sku=true
+type skuHandler struct{ check bool }
+
+func newSKUHandler(args []string) (rv.FieldHandler, error) {
+	return skuHandler{check: args[0] == "true"}, nil
+}
+
+func (h skuHandler) Run(req rv.Request, field *rv.Field) {
+	if s, ok := field.Value.(string); h.check && ok {
+		var letters string
+		var digits int
+		if n, _ := fmt.Sscanf(s, "%3s-%d", &letters, &digits); n != 2 {
+			field.Errors = append(field.Errors, fmt.Errorf("%#v is not a SKU", s))
+		}
+	}
+}
+
+func init() {
+	if err := rv.RegisterHandler("sku", newSKUHandler); err != nil {
+		panic(err)
+	}
+}
+
 type SimpleRequest struct {
 	SimpleArg string `rv:"query.arg"`
 }
@@ -117,7 +186,7 @@ var _ = Describe("RequestHandler", func() {
 			y := struct{}{}
 			expected := map[string]rv.FieldHandlers{
 				"Foo": rv.FieldHandlers{
-					rv.SourceFieldHandler{Source: rv.QUERY, Field: "foo"},
+					rv.SourceFieldHandler{Source: rv.QUERY, Field: "foo", List: true},
 					rv.DefaultHandler{Default: []string{"one", "two"}},
 					rv.ListHandler{SubHandlers: rv.FieldHandlers{
 						rv.TypeHandler{Type: "string"},
@@ -183,6 +252,1165 @@ var _ = Describe("RequestHandler", func() {
 		})
 	})
 
+	Describe("Run with list headers", func() {
+		It("trims the spaces around each element", func() {
+			type headers struct {
+				Accept []string `rv:"header.Accept"`
+				Tags   []string `rv:"query.tags"`
+			}
+			rh, err := rv.NewRequestHandler(headers{})
+			Expect(err).NotTo(HaveOccurred())
+
+			h := headers{}
+			err, fieldErrs := rh.Run(&rv.BasicRequest{
+				Query:  "tags=a,+b",
+				Header: http.Header{"Accept": []string{"text/html, application/json"}},
+			}, &h)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(BeEmpty())
+			Expect(h.Accept).To(Equal([]string{"text/html", "application/json"}))
+			Expect(h.Tags).To(Equal([]string{"a", " b"}))
+		})
+	})
+
+	Describe("Run with multipart uploads", func() {
+		type uploadStruct struct {
+			Caption string                  `rv:"form.caption"`
+			Avatar  *multipart.FileHeader   `rv:"file.avatar required=true maxsize=1KB contenttypes=image/*"`
+			Extras  []*multipart.FileHeader `rv:"file.extras maxsize=4"`
+		}
+
+		It("binds text parts and files", func() {
+			body, contentType := multipartBody(map[string]string{"caption": "me"},
+				testFile{"avatar", "me.png", "image/png", "PNG"},
+				testFile{"extras", "a.txt", "text/plain", "a"},
+				testFile{"extras", "b.txt", "text/plain", "b"})
+			req := &rv.BasicRequest{Body: body, Header: http.Header{"Content-Type": []string{contentType}}}
+			rh, err := rv.NewRequestHandler(uploadStruct{})
+			Expect(err).NotTo(HaveOccurred())
+
+			us := uploadStruct{}
+			err, fieldErrs := rh.Run(req, &us)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(BeEmpty())
+			Expect(us.Caption).To(Equal("me"))
+			Expect(us.Avatar.Filename).To(Equal("me.png"))
+			Expect(us.Extras).To(HaveLen(2))
+			Expect(us.Extras[1].Filename).To(Equal("b.txt"))
+		})
+
+		It("validates each file", func() {
+			body, contentType := multipartBody(nil,
+				testFile{"avatar", "me.pdf", "application/pdf", "PDF"},
+				testFile{"extras", "a.txt", "text/plain", "too large"})
+			req := &rv.BasicRequest{Body: body, Header: http.Header{"Content-Type": []string{contentType}}}
+			rh, _ := rv.NewRequestHandler(uploadStruct{})
+
+			err, fieldErrs := rh.Run(req, &uploadStruct{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(HaveKey("Avatar"))
+			Expect(fieldErrs).To(HaveKey("Extras"))
+		})
+	})
+
+	Describe("Run with nested structs", func() {
+		type geo struct {
+			Lat float64 `rv:"json.lat range=-90,90"`
+		}
+		type address struct {
+			Zip string `rv:"json.zip required=true"`
+			Geo *geo   `rv:"json.geo"`
+		}
+		type person struct {
+			Name    string   `rv:"json.name"`
+			Address address  `rv:"json.address"`
+			Billing *address `rv:"json.billing"`
+		}
+
+		var rh *rv.RequestHandler
+
+		BeforeEach(func() {
+			var err error
+			rh, err = rv.NewRequestHandler(person{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("fills nested structs and pointers to structs", func() {
+			req := &rv.BasicRequest{Body: `{"name": "Al", "address": {"zip": "78701", "geo": {"lat": 30.2}}, "billing": {"zip": "10001"}}`}
+			p := person{}
+			err, fieldErrs := rh.Run(req, &p)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(BeEmpty())
+			Expect(p.Name).To(Equal("Al"))
+			Expect(p.Address.Zip).To(Equal("78701"))
+			Expect(p.Address.Geo).To(Equal(&geo{Lat: 30.2}))
+			Expect(p.Billing).To(Equal(&address{Zip: "10001"}))
+		})
+
+		It("leaves absent nested structs alone", func() {
+			req := &rv.BasicRequest{Body: `{"name": "Al"}`}
+			p := person{}
+			err, fieldErrs := rh.Run(req, &p)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(BeEmpty())
+			Expect(p.Billing).To(BeNil())
+		})
+
+		It("reports nested errors with dotted paths", func() {
+			req := &rv.BasicRequest{Body: `{"address": {"geo": {"lat": 100}}, "billing": "nope"}`}
+			err, fieldErrs := rh.Run(req, &person{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(HaveLen(3))
+			Expect(fieldErrs).To(HaveKey("Address.Zip"))
+			Expect(fieldErrs).To(HaveKey("Address.Geo.Lat"))
+			Expect(fieldErrs).To(HaveKey("Billing"))
+		})
+
+		It("supports recursive struct types", func() {
+			type node struct {
+				Name  string `rv:"json.name"`
+				Child *node  `rv:"json.child"`
+			}
+			rh, err := rv.NewRequestHandler(node{})
+			Expect(err).NotTo(HaveOccurred())
+
+			n := node{}
+			err, fieldErrs := rh.Run(&rv.BasicRequest{Body: `{"name": "a", "child": {"name": "b"}}`}, &n)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(BeEmpty())
+			Expect(n.Child.Name).To(Equal("b"))
+		})
+	})
+
+	Describe("Run with slices of structs", func() {
+		type lineItem struct {
+			SKU      string `rv:"json.sku required=true"`
+			Quantity int    `rv:"json.quantity range=1,10 default=1"`
+		}
+		type order struct {
+			Items []lineItem  `rv:"json.items"`
+			Gifts []*lineItem `rv:"json.gifts"`
+		}
+
+		var rh *rv.RequestHandler
+
+		BeforeEach(func() {
+			var err error
+			rh, err = rv.NewRequestHandler(order{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("fills each element from the JSON array", func() {
+			req := &rv.BasicRequest{Body: `{"items": [{"sku": "a", "quantity": 2}, {"sku": "b"}], "gifts": [{"sku": "c"}]}`}
+			o := order{}
+			err, fieldErrs := rh.Run(req, &o)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(BeEmpty())
+			Expect(o.Items).To(Equal([]lineItem{{"a", 2}, {"b", 1}}))
+			Expect(o.Gifts).To(Equal([]*lineItem{{"c", 1}}))
+		})
+
+		It("reports element errors keyed by index", func() {
+			req := &rv.BasicRequest{Body: `{"items": [{"sku": "a"}, {"sku": "b"}, {"quantity": 20}]}`}
+			o := order{}
+			err, fieldErrs := rh.Run(req, &o)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(HaveLen(2))
+			Expect(fieldErrs).To(HaveKey("Items[2].SKU"))
+			Expect(fieldErrs).To(HaveKey("Items[2].Quantity"))
+			Expect(o.Items).To(BeNil())
+		})
+	})
+
+	Describe("Run with map fields", func() {
+		type listing struct {
+			Labels map[string]string `rv:"json.labels"`
+			Counts map[string]int    `rv:"json.counts range=0,10"`
+			Filter map[string]string `rv:"query.filter key:options=status,owner"`
+		}
+
+		var rh *rv.RequestHandler
+
+		BeforeEach(func() {
+			var err error
+			rh, err = rv.NewRequestHandler(listing{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("generates a map handler with key and value handlers", func() {
+			y := struct{}{}
+			Expect(rh.Fields["Filter"]).To(Equal(rv.FieldHandlers{
+				rv.SourceFieldHandler{Source: rv.QUERY, Field: "filter", Map: true},
+				rv.MapHandler{
+					KeyHandlers: rv.FieldHandlers{
+						rv.TypeHandler{Type: "string"},
+						rv.OptionsHandler{Options: map[string]struct{}{"status": y, "owner": y}},
+					},
+					ValueHandlers: rv.FieldHandlers{rv.TypeHandler{Type: "string"}},
+				},
+			}))
+		})
+
+		It("fills maps from JSON objects and bracketed query keys", func() {
+			req := &rv.BasicRequest{
+				Body:  `{"labels": {"env": "prod", "team": "web"}, "counts": {"a": 1, "b": 2}}`,
+				Query: "filter[status]=open&filter[owner]=me&other=1",
+			}
+			l := listing{}
+			err, fieldErrs := rh.Run(req, &l)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(BeEmpty())
+			Expect(l.Labels).To(Equal(map[string]string{"env": "prod", "team": "web"}))
+			Expect(l.Counts).To(Equal(map[string]int{"a": 1, "b": 2}))
+			Expect(l.Filter).To(Equal(map[string]string{"status": "open", "owner": "me"}))
+		})
+
+		It("validates keys and values, keying errors by map key", func() {
+			req := &rv.BasicRequest{
+				Body:  `{"counts": {"a": "1", "b": "20"}}`,
+				Query: "filter[color]=red",
+			}
+			l := listing{}
+			err, fieldErrs := rh.Run(req, &l)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(HaveLen(2))
+			Expect(fieldErrs).To(HaveKey("Counts[b]"))
+			Expect(fieldErrs).To(HaveKey("Filter[color]"))
+			Expect(l.Counts).To(BeNil())
+		})
+
+		It("rejects key handlers on fields that aren't maps", func() {
+			_, err := rv.NewRequestHandler(struct {
+				Foo string `rv:"query.foo key:options=a"`
+			}{})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Run with pointer fields", func() {
+		type patch struct {
+			Name  *string    `rv:"json.name"`
+			Age   *int       `rv:"json.age"`
+			Tags  *[]string  `rv:"json.tags"`
+			When  *time.Time `rv:"json.when"`
+			Limit *int       `rv:"query.limit default=10"`
+		}
+
+		var rh *rv.RequestHandler
+
+		BeforeEach(func() {
+			var err error
+			rh, err = rv.NewRequestHandler(patch{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("leaves pointers nil when values are absent", func() {
+			p := patch{}
+			err, fieldErrs := rh.Run(&rv.BasicRequest{Body: `{}`}, &p)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(BeEmpty())
+			Expect(p.Name).To(BeNil())
+			Expect(p.Age).To(BeNil())
+			Expect(p.Tags).To(BeNil())
+			Expect(p.When).To(BeNil())
+			Expect(*p.Limit).To(Equal(10))
+		})
+
+		It("points to the converted values when present", func() {
+			p := patch{}
+			err, fieldErrs := rh.Run(&rv.BasicRequest{
+				Body:  `{"name": "Al", "age": "42", "tags": ["a", "b"], "when": "2015-01-01"}`,
+				Query: "limit=5",
+			}, &p)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(BeEmpty())
+			Expect(*p.Name).To(Equal("Al"))
+			Expect(*p.Age).To(Equal(42))
+			Expect(*p.Tags).To(Equal([]string{"a", "b"}))
+			Expect(*p.When).To(Equal(time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC)))
+			Expect(*p.Limit).To(Equal(5))
+		})
+
+		It("resets pointers to nil for explicit JSON nulls and leaves absent fields alone", func() {
+			name, age := "Al", 42
+			p := patch{Name: &name, Age: &age}
+			err, fieldErrs := rh.Run(&rv.BasicRequest{Body: `{"name": null}`}, &p)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(BeEmpty())
+			Expect(p.Name).To(BeNil())
+			Expect(*p.Age).To(Equal(42))
+		})
+
+		It("only runs required on absent pointers with validators", func() {
+			type page struct {
+				Page  *int    `rv:"query.page range=1,10"`
+				Sort  *string `rv:"query.sort options=asc,desc"`
+				Limit *int    `rv:"query.limit range=1,100 required=true"`
+			}
+			rh, err := rv.NewRequestHandler(page{})
+			Expect(err).NotTo(HaveOccurred())
+
+			p := page{}
+			err, fieldErrs := rh.RunOrdered(&rv.BasicRequest{}, &p)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(HaveLen(1))
+			Expect(fieldErrs[0].Name).To(Equal("Limit"))
+			Expect(fieldErrs[0].Errors).To(HaveLen(1))
+			Expect(fieldErrs[0].Errors[0]).To(MatchError("required field missing"))
+			Expect(p.Page).To(BeNil())
+			Expect(p.Sort).To(BeNil())
+
+			err, fieldErrs = rh.RunOrdered(&rv.BasicRequest{Query: "page=11&sort=up&limit=5"}, &p)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(HaveLen(2))
+			Expect(fieldErrs[0].Name).To(Equal("Page"))
+			Expect(fieldErrs[1].Name).To(Equal("Sort"))
+		})
+	})
+
+	Describe("Run with custom types", func() {
+		type custom struct {
+			IP     net.IP  `rv:"query.ip"`
+			Color  color   `rv:"query.color"`
+			Colors []color `rv:"query.colors"`
+			Origin *point  `rv:"json.origin"`
+			Price  cents   `rv:"query.price"`
+			Prices []cents `rv:"json.prices"`
+		}
+
+		var rh *rv.RequestHandler
+
+		BeforeEach(func() {
+			var err error
+			rh, err = rv.NewRequestHandler(custom{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("uses custom type handlers for those fields", func() {
+			Expect(rh.Fields["IP"]).To(ContainElement(rv.CustomTypeHandler{Type: reflect.TypeOf(net.IP{})}))
+		})
+
+		It("converts values with UnmarshalText, UnmarshalJSON and registered converters", func() {
+			c := custom{}
+			err, fieldErrs := rh.Run(&rv.BasicRequest{
+				Query: "ip=10.0.0.1&color=blue&colors=red,blue&price=$1.25",
+				Body:  `{"origin": [3, 4], "prices": ["$1", "$2.50"]}`,
+			}, &c)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(BeEmpty())
+			Expect(c.IP.Equal(net.IPv4(10, 0, 0, 1))).To(BeTrue())
+			Expect(c.Color).To(Equal(color(2)))
+			Expect(c.Colors).To(Equal([]color{1, 2}))
+			Expect(c.Origin).To(Equal(&point{3, 4}))
+			Expect(c.Price).To(Equal(cents(125)))
+			Expect(c.Prices).To(Equal([]cents{100, 250}))
+		})
+
+		It("reports conversion errors", func() {
+			err, fieldErrs := rh.Run(&rv.BasicRequest{
+				Query: "ip=nope&color=green&price=12",
+				Body:  `{"origin": {"x": 1}}`,
+			}, &custom{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(HaveLen(4))
+			Expect(fieldErrs["Color"].Errors[0]).To(MatchError(`unknown color "green"`))
+		})
+	})
+
+	Describe("RegisterHandler", func() {
+		It("makes the handler available in tags", func() {
+			type products struct {
+				SKU  string   `rv:"query.sku sku=true"`
+				SKUs []string `rv:"query.skus sku=true"`
+			}
+			rh, err := rv.NewRequestHandler(products{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rh.Fields["SKU"]).To(ContainElement(skuHandler{check: true}))
+
+			p := products{}
+			err, fieldErrs := rh.Run(&rv.BasicRequest{Query: "sku=ABC-123&skus=ABC-1,nope"}, &p)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(HaveLen(1))
+			Expect(fieldErrs["SKUs"].Errors).To(Equal([]error{fmt.Errorf(`"nope" is not a SKU`)}))
+			Expect(p.SKU).To(Equal("ABC-123"))
+		})
+
+		It("rejects names of built-in handlers", func() {
+			Expect(rv.RegisterHandler("range", newSKUHandler)).To(MatchError(`handler "range" conflicts with a built-in handler`))
+		})
+
+		It("rejects tag keys which don't name handlers", func() {
+			for _, key := range []string{"sep", "repeated", "type", "strict", "bases", "leadingzeros", "nonfinite"} {
+				Expect(rv.RegisterHandler(key, newSKUHandler)).To(HaveOccurred(), key)
+			}
+		})
+
+		It("rejects names which are already registered", func() {
+			Expect(rv.RegisterHandler("sku", newSKUHandler)).To(MatchError(`handler "sku" is already registered`))
+		})
+
+		It("rejects names which can't be used in tags", func() {
+			Expect(rv.RegisterHandler("a b", newSKUHandler)).To(HaveOccurred())
+			Expect(rv.RegisterHandler("a=b", newSKUHandler)).To(HaveOccurred())
+			Expect(rv.RegisterHandler("", newSKUHandler)).To(HaveOccurred())
+		})
+	})
+
+	Describe("WithHandler", func() {
+		type scoped struct {
+			Code  string `rv:"query.code upper=true"`
+			Inner struct {
+				Code string `rv:"json.code upper=true"`
+			} `rv:"json.inner"`
+		}
+
+		upper := func(args []string) (rv.FieldHandler, error) {
+			return &mockHandler{errs: []error{fmt.Errorf("not upper")}}, nil
+		}
+
+		It("makes the handler available only to that RequestHandler and its nested structs", func() {
+			rh, err := rv.NewRequestHandler(scoped{}, rv.WithHandler("upper", upper))
+			Expect(err).NotTo(HaveOccurred())
+
+			err, fieldErrs := rh.Run(&rv.BasicRequest{Query: "code=a", Body: `{"inner": {"code": "b"}}`}, &scoped{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(HaveKey("Code"))
+			Expect(fieldErrs).To(HaveKey("Inner.Code"))
+
+			_, err = rv.NewRequestHandler(scoped{})
+			Expect(err).To(MatchError("Code: Invalid handler: upper; Inner.Code: Invalid handler: upper"))
+		})
+
+		It("rejects names of built-in handlers", func() {
+			_, err := rv.NewRequestHandler(scoped{}, rv.WithHandler("default", upper))
+			Expect(err).To(MatchError(`handler "default" conflicts with a built-in handler`))
+		})
+	})
+
+	Describe("JSON numbers", func() {
+		type numbers struct {
+			ID    int64   `rv:"json.id"`
+			Page  int     `rv:"json.page"`
+			Small uint8   `rv:"json.small"`
+			Price float64 `rv:"json.price"`
+		}
+
+		var rh *rv.RequestHandler
+
+		BeforeEach(func() {
+			var err error
+			rh, err = rv.NewRequestHandler(numbers{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("converts integers exactly", func() {
+			n := numbers{}
+			err, fieldErrs := rh.Run(&rv.BasicRequest{Body: `{"id": 9007199254740993, "page": 2, "small": 255, "price": 9.99}`}, &n)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(BeEmpty())
+			Expect(n).To(Equal(numbers{ID: 9007199254740993, Page: 2, Small: 255, Price: 9.99}))
+		})
+
+		It("rejects integers which may have been rounded by a Request without a raw body", func() {
+			req := struct{ rv.Request }{&rv.BasicRequest{Body: `{"id": 9007199254740993, "page": 2}`}}
+			err, fieldErrs := rh.Run(req, &numbers{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(HaveLen(1))
+			Expect(fieldErrs["ID"].Errors).To(ConsistOf(MatchError("number 9.007199254740992e+15 may have been rounded, can't be represented exactly as int64")))
+		})
+
+		It("leaves the numbers BodyJSON returns as float64", func() {
+			Expect((&rv.BasicRequest{Body: `{"id": 2}`}).BodyJSON()).To(Equal(map[string]interface{}{"id": 2.0}))
+		})
+
+		It("reports fractions and overflows", func() {
+			err, fieldErrs := rh.Run(&rv.BasicRequest{Body: `{"page": 2.5, "small": 256}`}, &numbers{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs["Page"].Errors).To(ConsistOf(MatchError("number 2.5 has a fractional part, can't be represented as int")))
+			Expect(fieldErrs["Small"].Errors).To(ConsistOf(MatchError("uint 256 can't be represented as uint8")))
+		})
+	})
+
+	Describe("RunOrdered", func() {
+		type item struct {
+			Qty int `rv:"json.qty range=1,5"`
+		}
+		type ordered struct {
+			Zeta  int    `rv:"query.zeta"`
+			Items []item `rv:"json.items"`
+			Alpha int    `rv:"query.alpha"`
+		}
+
+		It("returns field errors in declaration order, with nested fields in path order", func() {
+			rh, err := rv.NewRequestHandler(ordered{})
+			Expect(err).NotTo(HaveOccurred())
+
+			body := `{"items": [{"qty": 9}, {"qty": 1}, {"qty": 9}, {"qty": 1}, {"qty": 1}, {"qty": 1}, {"qty": 1}, {"qty": 1}, {"qty": 1}, {"qty": 1}, {"qty": 9}]}`
+			for i := 0; i < 10; i++ {
+				err, fieldErrs := rh.RunOrdered(&rv.BasicRequest{Query: "zeta=z&alpha=a", Body: body}, &ordered{})
+				Expect(err).NotTo(HaveOccurred())
+				names := []string{}
+				for _, fe := range fieldErrs {
+					names = append(names, fe.Name)
+				}
+				Expect(names).To(Equal([]string{"Zeta", "Items[0].Qty", "Items[2].Qty", "Items[10].Qty", "Alpha"}))
+			}
+		})
+
+		It("returns the same fields as Run", func() {
+			rh, err := rv.NewRequestHandler(ordered{})
+			Expect(err).NotTo(HaveOccurred())
+
+			req := &rv.BasicRequest{Query: "zeta=z", Body: `{"items": [{"qty": 9}]}`}
+			_, fieldErrs := rh.RunOrdered(req, &ordered{})
+			_, fieldMap := rh.Run(req, &ordered{})
+			Expect(fieldErrs.Map()).To(Equal(fieldMap))
+		})
+	})
+
+	Describe("Halting", func() {
+		type halting struct {
+			Page  int    `rv:"query.page range=1,10"`
+			Sort  string `rv:"query.sort options=asc,desc"`
+			Limit int    `rv:"query.limit required=true"`
+		}
+
+		It("doesn't validate values which couldn't be converted", func() {
+			rh, err := rv.NewRequestHandler(halting{})
+			Expect(err).NotTo(HaveOccurred())
+
+			err, fieldErrs := rh.Run(&rv.BasicRequest{Query: "page=two&sort=up&limit=x"}, &halting{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs["Page"].Errors).To(ConsistOf(MatchError(`strconv.ParseInt: parsing "two": invalid syntax`)))
+			Expect(fieldErrs["Sort"].Errors).To(ConsistOf(MatchError(`Expected one of []string{"asc", "desc"}, got "up"`)))
+			Expect(fieldErrs["Limit"].Errors).To(HaveLen(1))
+		})
+
+		It("stops at the first field with errors with WithFailFast", func() {
+			rh, err := rv.NewRequestHandler(halting{}, rv.WithFailFast(true))
+			Expect(err).NotTo(HaveOccurred())
+
+			err, fieldErrs := rh.Run(&rv.BasicRequest{Query: "page=two&sort=up"}, &halting{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(HaveLen(1))
+
+			h := halting{}
+			err, fieldErrs = rh.Run(&rv.BasicRequest{Query: "page=2&sort=asc&limit=5"}, &h)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(BeEmpty())
+			Expect(h).To(Equal(halting{Page: 2, Sort: "asc", Limit: 5}))
+		})
+	})
+
+	Describe("Strict types", func() {
+		type strictStruct struct {
+			Page    int               `rv:"json.page"`
+			Active  bool              `rv:"json.active"`
+			Name    string            `rv:"json.name"`
+			IDs     []int             `rv:"json.ids"`
+			Counts  map[string]int    `rv:"json.counts"`
+			Limit   int               `rv:"json.limit default=10"`
+			Loose   int               `rv:"json.loose strict=false"`
+			Query   int               `rv:"query.q"`
+			Color   color             `rv:"json.color"`
+			Labels  map[string]string `rv:"json.labels"`
+			Created time.Time         `rv:"json.created"`
+		}
+
+		var rh *rv.RequestHandler
+
+		BeforeEach(func() {
+			var err error
+			rh, err = rv.NewRequestHandler(strictStruct{}, rv.WithStrictTypes(true))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("accepts JSON values of the right type and parses other sources", func() {
+			ss := strictStruct{}
+			err, fieldErrs := rh.Run(&rv.BasicRequest{
+				Query: "q=3",
+				Body:  `{"page": 2, "active": true, "name": "x", "ids": [1, 2], "counts": {"a": 1}, "loose": "4", "color": "red", "created": "2015-01-01"}`,
+			}, &ss)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(BeEmpty())
+			Expect(ss).To(Equal(strictStruct{
+				Page: 2, Active: true, Name: "x", IDs: []int{1, 2}, Counts: map[string]int{"a": 1},
+				Limit: 10, Loose: 4, Query: 3, Color: color(1), Created: time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC),
+			}))
+		})
+
+		It("rejects JSON values of the wrong type", func() {
+			err, fieldErrs := rh.Run(&rv.BasicRequest{
+				Body: `{"page": "2", "active": 1, "name": 5, "ids": [1, "2"], "counts": {"a": "1"}}`,
+			}, &strictStruct{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs["Page"].Errors).To(ConsistOf(MatchError("expected number, got string")))
+			Expect(fieldErrs["Active"].Errors).To(ConsistOf(MatchError("expected boolean, got number")))
+			Expect(fieldErrs["Name"].Errors).To(ConsistOf(MatchError("expected string, got number")))
+			Expect(fieldErrs["IDs[1]"].Errors).To(ConsistOf(MatchError("expected number, got string")))
+			Expect(fieldErrs["Counts[a]"].Errors).To(ConsistOf(MatchError("expected number, got string")))
+		})
+
+		It("rejects a single value for a slice field", func() {
+			err, fieldErrs := rh.Run(&rv.BasicRequest{Body: `{"ids": "1,2"}`}, &strictStruct{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs["IDs"].Errors).To(ConsistOf(MatchError("expected array, got string")))
+		})
+
+		It("can be turned on for a single field", func() {
+			rh, err := rv.NewRequestHandler(struct {
+				Page int `rv:"json.page strict=true"`
+				Size int `rv:"json.size"`
+			}{})
+			Expect(err).NotTo(HaveOccurred())
+
+			err, fieldErrs := rh.Run(&rv.BasicRequest{Body: `{"page": "2", "size": "3"}`}, &struct {
+				Page int `rv:"json.page strict=true"`
+				Size int `rv:"json.size"`
+			}{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(HaveKey("Page"))
+			Expect(fieldErrs).NotTo(HaveKey("Size"))
+		})
+	})
+
+	Describe("Number formats", func() {
+		type numbers struct {
+			Page  int     `rv:"query.page"`
+			Color uint32  `rv:"query.color bases=hex"`
+			Mode  []int   `rv:"query.mode bases=octal,decimal"`
+			Code  int     `rv:"query.code leadingzeros=false"`
+			Ratio float64 `rv:"query.ratio nonfinite=true"`
+		}
+
+		It("uses the default format unless a field changes it", func() {
+			rh, err := rv.NewRequestHandler(numbers{})
+			Expect(err).NotTo(HaveOccurred())
+
+			n := numbers{}
+			err, fieldErrs := rh.Run(&rv.BasicRequest{Query: "page=010&color=0xFF00FF&mode=0o755,644&code=42&ratio=Inf"}, &n)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(BeEmpty())
+			Expect(n).To(Equal(numbers{Page: 10, Color: 0xFF00FF, Mode: []int{0755, 644}, Code: 42, Ratio: math.Inf(1)}))
+
+			err, fieldErrs = rh.Run(&rv.BasicRequest{Query: "page=0x10&code=042"}, &n)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs["Page"].Errors).To(ConsistOf(MatchError("hexadecimal numbers are not allowed, got '0x10'")))
+			Expect(fieldErrs["Code"].Errors).To(ConsistOf(MatchError("leading zeros are not allowed, got '042'")))
+		})
+
+		It("uses the format from WithNumberFormat", func() {
+			rh, err := rv.NewRequestHandler(numbers{}, rv.WithNumberFormat(rv.NumberFormat{Binary: true, NoLeadingZeros: true}))
+			Expect(err).NotTo(HaveOccurred())
+
+			n := numbers{}
+			err, fieldErrs := rh.Run(&rv.BasicRequest{Query: "page=0b11&color=0b11"}, &n)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n.Page).To(Equal(3))
+			Expect(fieldErrs["Color"].Errors).To(ConsistOf(MatchError("binary numbers are not allowed, got '0b11'")))
+
+			err, fieldErrs = rh.Run(&rv.BasicRequest{Query: "page=010"}, &n)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(HaveKey("Page"))
+		})
+
+		It("rejects invalid number options", func() {
+			_, err := rv.NewRequestHandler(struct {
+				Name string `rv:"query.name bases=hex"`
+			}{})
+			Expect(err).To(MatchError("Name: bases can only be used on number fields"))
+
+			_, err = rv.NewRequestHandler(struct {
+				Page int `rv:"query.page bases=roman"`
+			}{})
+			Expect(err).To(MatchError("Page: Expected bases from hex, octal, binary and decimal, got 'roman'"))
+		})
+	})
+
+	Describe("Repeated keys", func() {
+		type repeatedStruct struct {
+			IDs   []int    `rv:"query.id"`
+			Tags  []string `rv:"query.tag sep=|"`
+			Names []string `rv:"query.name sep=none"`
+			Page  int      `rv:"query.page repeated=false"`
+			Sort  string   `rv:"query.sort"`
+		}
+
+		var rh *rv.RequestHandler
+
+		BeforeEach(func() {
+			var err error
+			rh, err = rv.NewRequestHandler(repeatedStruct{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("binds repeated and separated values into slice fields", func() {
+			rs := repeatedStruct{}
+			err, fieldErrs := rh.Run(&rv.BasicRequest{Query: "id=1&id=2,3&tag=a|b&tag=c&name=Smith,+J&name=Doe&sort=a&sort=b"}, &rs)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(BeEmpty())
+			Expect(rs).To(Equal(repeatedStruct{
+				IDs:   []int{1, 2, 3},
+				Tags:  []string{"a", "b", "c"},
+				Names: []string{"Smith, J", "Doe"},
+				Sort:  "a",
+			}))
+		})
+
+		It("rejects repeated keys on fields with repeated=false", func() {
+			err, fieldErrs := rh.Run(&rv.BasicRequest{Query: "page=1&page=2"}, &repeatedStruct{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(HaveKey("Page"))
+			Expect(fieldErrs["Page"].Errors).To(ConsistOf(MatchError("'page' given 2 times, expected once")))
+		})
+
+		It("rejects sep and repeated on the wrong kinds of field", func() {
+			_, err := rv.NewRequestHandler(struct {
+				Page int `rv:"query.page sep=;"`
+			}{})
+			Expect(err).To(MatchError("Page: sep can only be used on slice fields"))
+
+			_, err = rv.NewRequestHandler(struct {
+				IDs []int `rv:"query.id repeated=false"`
+			}{})
+			Expect(err).To(MatchError("IDs: repeated can't be used on slice or map fields"))
+
+			_, err = rv.NewRequestHandler(struct {
+				Page int `rv:"query.page repeated=maybe"`
+			}{})
+			Expect(err).To(MatchError(`Page: invalid value for repeated: "maybe"`))
+		})
+	})
+
+	Describe("MultiHandler", func() {
+		type pathParams struct {
+			ID int `rv:"path.id"`
+		}
+		type queryParams struct {
+			Page int `rv:"query.page range=1,100"`
+		}
+		type body struct {
+			Name    string `rv:"json.name required=true"`
+			Address struct {
+				Zip string `rv:"json.zip required=true"`
+			} `rv:"json.address"`
+		}
+		type container struct {
+			Parent      *struct{ Count int }
+			PathParams  pathParams
+			QueryParams *queryParams
+			Body        body
+			Raw         string `rv:"form.raw"`
+			Started     time.Time
+		}
+
+		var mh *rv.MultiHandler
+
+		BeforeEach(func() {
+			var err error
+			mh, err = rv.NewMultiHandler(&container{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("fills every rv-tagged struct field", func() {
+			c := container{}
+			err, fieldErrs := mh.Run(&rv.BasicRequest{
+				Path:  map[string]string{"id": "7"},
+				Query: "page=2",
+				Body:  `{"name": "Al", "address": {"zip": "78701"}}`,
+			}, &c)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(BeEmpty())
+			Expect(c.Parent).To(BeNil())
+			Expect(c.PathParams).To(Equal(pathParams{ID: 7}))
+			Expect(c.QueryParams).To(Equal(&queryParams{Page: 2}))
+			Expect(c.Body.Name).To(Equal("Al"))
+			Expect(c.Body.Address.Zip).To(Equal("78701"))
+			Expect(c.Raw).To(BeEmpty())
+		})
+
+		It("returns the errors of all the fields prefixed with their path", func() {
+			err := mh.Fill(&rv.BasicRequest{
+				Path:  map[string]string{"id": "x"},
+				Query: "page=0",
+				Body:  `{"address": {}}`,
+			}, &container{})
+			Expect(err).To(MatchError(`PathParams.ID: strconv.ParseInt: parsing "x": invalid syntax; ` +
+				`QueryParams.Page: 0 not in range 1, 100; ` +
+				`Body.Name: required field missing; ` +
+				`Body.Address.Zip: required field missing`))
+
+			err, fieldErrs := mh.Run(&rv.BasicRequest{Query: "page=0"}, &container{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(HaveKey("QueryParams.Page"))
+			Expect(fieldErrs["QueryParams.Page"].Errors[0].(*rv.ValidationError).Field).To(Equal("QueryParams.Page"))
+		})
+
+		It("reads the body once for every field", func() {
+			type both struct {
+				A struct {
+					Name string `rv:"json.name"`
+				}
+				B struct {
+					Name string `rv:"json.name"`
+				}
+			}
+			mh, err := rv.NewMultiHandler(both{})
+			Expect(err).NotTo(HaveOccurred())
+
+			b := both{}
+			Expect(mh.Fill(&rv.BasicRequest{Body: `{"name": "Al"}`}, &b)).To(Succeed())
+			Expect(b.A.Name).To(Equal("Al"))
+			Expect(b.B.Name).To(Equal("Al"))
+		})
+
+		It("reports bad tags and containers", func() {
+			_, err := rv.NewMultiHandler(struct {
+				Query queryParams
+				Bad   struct {
+					Page int `rv:"query.page range=a,b"`
+				}
+			}{})
+			Expect(err).To(MatchError(`Bad.Page: invalid range a,b for int field: strconv.ParseInt: parsing "a": invalid syntax`))
+
+			_, err = rv.NewMultiHandler(struct{ Count int }{})
+			Expect(err).To(MatchError("No fields with rv tags found in struct { Count int }"))
+
+			type base struct {
+				Page int `rv:"query.page"`
+			}
+			type embedding struct {
+				base
+			}
+			_, err = rv.NewMultiHandler(struct {
+				Query queryParams
+				Page  struct{ embedding }
+			}{})
+			Expect(err).To(MatchError("Page.embedding: rv tags on embedded struct embedding.base aren't bound"))
+
+			err, _ = mh.Run(&rv.BasicRequest{}, container{})
+			Expect(err).To(MatchError("Expected *rv_test.container, got rv_test.container"))
+		})
+	})
+
+	Describe("Bind", func() {
+		type bindArgs struct {
+			Page int    `rv:"query.page default=1 range=1,100"`
+			Sort string `rv:"query.sort options=asc,desc"`
+		}
+
+		It("fills a new struct", func() {
+			args, err := rv.Bind[bindArgs](&rv.BasicRequest{Query: "sort=asc"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(args).To(Equal(bindArgs{Page: 1, Sort: "asc"}))
+		})
+
+		It("returns validation errors along with what could be filled", func() {
+			args, err := rv.Bind[bindArgs](&rv.BasicRequest{Query: "page=0&sort=asc"})
+			Expect(err).To(MatchError("Page: 0 not in range 1, 100"))
+			Expect(err).To(BeAssignableToTypeOf(rv.ValidationErrors{}))
+			Expect(args.Sort).To(Equal("asc"))
+		})
+
+		It("shares one RequestHandler for each type", func() {
+			Expect(rv.MustHandler[bindArgs]()).To(BeIdenticalTo(rv.MustHandler[bindArgs]()))
+		})
+
+		It("reports bad tags", func() {
+			type badArgs struct {
+				Page int `rv:"query.page default=abc"`
+			}
+			_, err := rv.Bind[badArgs](&rv.BasicRequest{})
+			Expect(err).To(MatchError(`Page: invalid default "abc": strconv.ParseInt: parsing "abc": invalid syntax`))
+			Expect(func() { rv.MustHandler[badArgs]() }).To(Panic())
+
+			_, err = rv.Bind[*bindArgs](&rv.BasicRequest{})
+			Expect(err).To(MatchError("Expected struct, got *rv_test.bindArgs"))
+		})
+
+		It("binds with a RequestHandler built with options", func() {
+			rh, err := rv.NewRequestHandler(bindArgs{}, rv.WithFailFast(true))
+			Expect(err).NotTo(HaveOccurred())
+
+			args, err := rv.BindWith[bindArgs](rh, &rv.BasicRequest{Query: "page=0&sort=up"})
+			Expect(err).To(MatchError("Page: 0 not in range 1, 100"))
+			Expect(args.Page).To(Equal(0))
+
+			_, err = rv.BindWith[struct{ Page int }](rh, &rv.BasicRequest{})
+			Expect(err).To(MatchError("Expected *rv_test.bindArgs, got *struct { Page int }"))
+		})
+	})
+
+	Describe("Name inference", func() {
+		type address struct {
+			ZipCode  string `rv:"json" json:"zip_code"`
+			LineTwo  string `rv:"json"`
+			Verified bool   `rv:"query"`
+		}
+		type namedStruct struct {
+			Page    int     `rv:"query" form:"p"`
+			PerPage int     `rv:"query"`
+			Address address `rv:"json"`
+		}
+
+		It("takes names from struct tags and the naming strategy", func() {
+			rh, err := rv.NewRequestHandler(namedStruct{}, rv.WithNaming(rv.KebabCase))
+			Expect(err).NotTo(HaveOccurred())
+
+			ns := namedStruct{}
+			err, fieldErrs := rh.Run(&rv.BasicRequest{
+				Query: "p=2&per-page=20&verified=true",
+				Body:  `{"address": {"zip_code": "12345", "line-two": "Apt 1"}}`,
+			}, &ns)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(BeEmpty())
+			Expect(ns).To(Equal(namedStruct{
+				Page:    2,
+				PerPage: 20,
+				Address: address{ZipCode: "12345", LineTwo: "Apt 1", Verified: true},
+			}))
+		})
+
+		It("uses the field name without a naming strategy", func() {
+			rh, err := rv.NewRequestHandler(namedStruct{})
+			Expect(err).NotTo(HaveOccurred())
+
+			ns := namedStruct{}
+			err, fieldErrs := rh.Run(&rv.BasicRequest{Query: "PerPage=20"}, &ns)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(BeEmpty())
+			Expect(ns.PerPage).To(Equal(20))
+		})
+	})
+
+	Describe("Tag checks", func() {
+		It("accepts valid tags", func() {
+			_, err := rv.NewRequestHandler(struct {
+				Page  int       `rv:"query.page default=1 range=1,100"`
+				Sort  string    `rv:"query.sort default=asc options=asc,desc"`
+				Size  float64   `rv:"query.size range=0.5,10 options=0.5,1,2.5"`
+				IDs   []int     `rv:"query.ids default=1,2 range=1,9"`
+				Tags  []string  `rv:"query.tags options=a,b"`
+				Color color     `rv:"query.color default=red"`
+				Flags []bool    `rv:"query.flags options=true,false"`
+				When  time.Time `rv:"query.when default=2015-01-01"`
+			}{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("allows quoted values", func() {
+			type quotedStruct struct {
+				Greeting string   `rv:"query.greeting default='hello world'"`
+				Pair     string   `rv:"query.pair options='a,b','c d'"`
+				Words    []string `rv:"query.words sep=' ' default='a,b'"`
+			}
+			rh, err := rv.NewRequestHandler(quotedStruct{})
+			Expect(err).NotTo(HaveOccurred())
+
+			qs := quotedStruct{}
+			err, fieldErrs := rh.Run(&rv.BasicRequest{Query: "pair=a,b&words=x+y"}, &qs)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(BeEmpty())
+			Expect(qs).To(Equal(quotedStruct{Greeting: "hello world", Pair: "a,b", Words: []string{"x", "y"}}))
+
+			qs = quotedStruct{}
+			err, fieldErrs = rh.Run(&rv.BasicRequest{Query: "pair=c+d"}, &qs)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fieldErrs).To(BeEmpty())
+			Expect(qs).To(Equal(quotedStruct{Greeting: "hello world", Pair: "c d", Words: []string{"a,b"}}))
+		})
+
+		It("reads backslashes outside quotes as written", func() {
+			type pathStruct struct {
+				Path string `rv:"query.path default=a\\b"`
+			}
+			rh, err := rv.NewRequestHandler(pathStruct{})
+			Expect(err).NotTo(HaveOccurred())
+
+			ps := pathStruct{}
+			Expect(rh.Fill(&rv.BasicRequest{}, &ps)).To(Succeed())
+			Expect(ps).To(Equal(pathStruct{Path: `a\b`}))
+		})
+
+		It("reports unterminated quotes and text after closing quotes", func() {
+			_, err := rv.NewRequestHandler(struct {
+				Greeting string `rv:"query.greeting default='hello world"`
+				Pair     string `rv:"query.pair options='a,b'c"`
+			}{})
+			Expect(err).To(MatchError(`Greeting: unterminated quoted value at column 24 of "query.greeting default='hello world"; ` +
+				`Pair: unexpected 'c' after quoted value at column 20 of "query.pair options='a,b'c"`))
+		})
+
+		It("reports malformed tags with their position", func() {
+			_, err := rv.NewRequestHandler(struct {
+				Greeting string `rv:"query.greeting =hello"`
+				Page     int    `rv:"query.page range=1,x"`
+			}{})
+			Expect(err).To(MatchError(`Greeting: missing key before '=' at column 16 of "query.greeting =hello"; ` +
+				`Page: invalid range 1,x for int field: strconv.ParseInt: parsing "x": invalid syntax`))
+		})
+
+		It("reports rv tags on embedded structs, which aren't bound", func() {
+			type base struct {
+				Page int `rv:"query.page"`
+			}
+			type embedding struct {
+				base
+			}
+			_, err := rv.NewRequestHandler(struct {
+				base
+				Name string `rv:"query.name"`
+				embedding
+				Nested base `rv:"json.nested"`
+			}{})
+			Expect(err).To(MatchError("base: rv tags on embedded struct base aren't bound; " +
+				"embedding: rv tags on embedded struct embedding.base aren't bound"))
+		})
+
+		It("reports every problem in the struct at once", func() {
+			type inner struct {
+				Zip int `rv:"json.zip range=z,a"`
+			}
+			_, err := rv.NewRequestHandler(struct {
+				Page    int               `rv:"query.page default=abc"`
+				Limit   int               `rv:"query.limit range=a,b"`
+				Size    uint8             `rv:"query.size range=10,1"`
+				Sort    int               `rv:"query.sort options=1,two,03"`
+				IDs     []int             `rv:"query.ids default=1,x"`
+				Name    string            `rv:"query.name default=zed range=a,m"`
+				When    time.Time         `rv:"query.when range=a,b"`
+				Dup     string            `rv:"query.dup required=true required=false"`
+				Unknown string            `rv:"query.unknown frobnicate=true"`
+				Counts  map[string]uint   `rv:"query.counts key:options=a,b range=1,-1"`
+				Address inner             `rv:"json.address"`
+				Ch      chan int          `rv:"query.ch"`
+				Labels  map[string]string `rv:"query.labels"`
+			}{})
+			Expect(err).To(HaveOccurred())
+
+			errs, ok := err.(rv.TagErrors)
+			Expect(ok).To(BeTrue())
+			messages := []string{}
+			for _, e := range errs {
+				messages = append(messages, e.Error())
+			}
+			Expect(messages).To(Equal([]string{
+				`Page: invalid default "abc": strconv.ParseInt: parsing "abc": invalid syntax`,
+				`Limit: invalid range a,b for int field: strconv.ParseInt: parsing "a": invalid syntax`,
+				`Size: invalid range 10,1: start is after end`,
+				`Sort: options []string{"03", "two"} can never match a int`,
+				`IDs: invalid default "1,x": strconv.ParseInt: parsing "x": invalid syntax`,
+				`Name: invalid default "zed": "zed" not in range "a", "m"`,
+				`When: range can't be used on time fields`,
+				`Dup: duplicate tag key required at column 25`,
+				`Unknown: Invalid handler: frobnicate`,
+				`Counts: invalid range 1,-1 for uint field: strconv.ParseUint: parsing "-1": invalid syntax`,
+				`Address.Zip: invalid range z,a for int field: strconv.ParseInt: parsing "z": invalid syntax`,
+				`Ch: 'chan' is not a supported type`,
+			}))
+		})
+	})
+
+	Describe("WithMaxBodySize", func() {
+		It("rejects a size which isn't positive", func() {
+			_, err := rv.NewRequestHandler(struct{}{}, rv.WithMaxBodySize(0))
+			Expect(err).To(MatchError("max body size must be positive, got 0"))
+		})
+	})
+
+	Describe("Fill", func() {
+		type address struct {
+			Zip string `rv:"json.zip required=true"`
+		}
+		type fillStruct struct {
+			Page    int     `rv:"query.page range=1,10"`
+			Address address `rv:"json.address"`
+		}
+
+		var rh *rv.RequestHandler
+
+		BeforeEach(func() {
+			var err error
+			rh, err = rv.NewRequestHandler(fillStruct{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns nil when there are no errors", func() {
+			fs := fillStruct{}
+			Expect(rh.Fill(&rv.BasicRequest{Query: "page=2"}, &fs)).To(Succeed())
+			Expect(fs.Page).To(Equal(2))
+		})
+
+		It("returns argument errors as they are", func() {
+			Expect(rh.Fill(&rv.BasicRequest{}, fillStruct{})).To(MatchError("Expected *rv_test.fillStruct, got rv_test.fillStruct"))
+		})
+
+		It("returns ValidationErrors for every field, with their paths and sources", func() {
+			err := rh.Fill(&rv.BasicRequest{Query: "page=20", Body: `{"address": {}}`}, &fillStruct{})
+			Expect(err).To(MatchError("Page: 20 not in range 1, 10; Address.Zip: required field missing"))
+
+			errs, ok := err.(rv.ValidationErrors)
+			Expect(ok).To(BeTrue())
+			Expect(errs).To(Equal(rv.ValidationErrors{
+				{Field: "Page", Source: "query.page", Rule: "range", Value: 20, Params: []string{"1", "10"}, Message: "20 not in range 1, 10"},
+				{Field: "Address.Zip", Source: "json.address.zip", Rule: "required", Message: "required field missing"},
+			}))
+		})
+
+		It("wraps errors from other handlers", func() {
+			rh, err := rv.NewRequestHandler(struct {
+				Foo string `rv:"query.foo fail=true"`
+			}{}, rv.WithHandler("fail", func([]string) (rv.FieldHandler, error) {
+				return &mockHandler{errs: []error{fmt.Errorf("ow")}}, nil
+			}))
+			Expect(err).NotTo(HaveOccurred())
+
+			err = rh.Fill(&rv.BasicRequest{Query: "foo=bar"}, &struct {
+				Foo string `rv:"query.foo fail=true"`
+			}{})
+			Expect(err).To(Equal(rv.ValidationErrors{
+				{Field: "Foo", Rule: "custom", Value: "bar", Message: "ow", Err: fmt.Errorf("ow")},
+			}))
+		})
+
+		It("doesn't change ValidationErrors returned by other handlers", func() {
+			shared := &rv.ValidationError{Rule: "shared", Message: "ow"}
+			type sharedStruct struct {
+				Foo string `rv:"query.foo fail=true"`
+				Bar string `rv:"query.bar fail=true"`
+			}
+			rh, err := rv.NewRequestHandler(sharedStruct{}, rv.WithHandler("fail", func([]string) (rv.FieldHandler, error) {
+				return &mockHandler{errs: []error{shared}}, nil
+			}))
+			Expect(err).NotTo(HaveOccurred())
+
+			err = rh.Fill(&rv.BasicRequest{Query: "foo=a&bar=b"}, &sharedStruct{})
+			Expect(err).To(Equal(rv.ValidationErrors{
+				{Field: "Foo", Source: "query.foo", Rule: "shared", Message: "ow"},
+				{Field: "Bar", Source: "query.bar", Rule: "shared", Message: "ow"},
+			}))
+			Expect(shared).To(Equal(&rv.ValidationError{Rule: "shared", Message: "ow"}))
+		})
+
+		It("gives nested list items their full source", func() {
+			type item struct {
+				Qty int `rv:"json.qty required=true"`
+			}
+			type order struct {
+				Items []item `rv:"json.items"`
+			}
+			rh, err := rv.NewRequestHandler(order{})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = rh.Fill(&rv.BasicRequest{Body: `{"items": [{"qty": 1}, {}]}`}, &order{})
+			errs, ok := err.(rv.ValidationErrors)
+			Expect(ok).To(BeTrue())
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("Items[1].Qty"))
+			Expect(errs[0].Source).To(Equal("json.items.1.qty"))
+		})
+	})
+
 	Describe("Bind", func() {
 		type testStruct struct {
 			Foo int `rv:"query.i"`
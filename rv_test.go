@@ -1,7 +1,15 @@
 package rv_test
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
 	"net/url"
+	"strings"
 
 	. "github.com/OwnLocal/rv"
 
@@ -9,6 +17,29 @@ import (
 	. "github.com/onsi/gomega"
 )
 
+type testFile struct {
+	field, name, contentType, content string
+}
+
+// multipartBody builds a multipart/form-data body from the text values
+// and files, returning the body and its Content-Type header.
+func multipartBody(values map[string]string, files ...testFile) (string, string) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for name, value := range values {
+		w.WriteField(name, value)
+	}
+	for _, f := range files {
+		h := textproto.MIMEHeader{}
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, f.field, f.name))
+		h.Set("Content-Type", f.contentType)
+		part, _ := w.CreatePart(h)
+		part.Write([]byte(f.content))
+	}
+	w.Close()
+	return buf.String(), w.FormDataContentType()
+}
+
 var _ = Describe("BasicRequest", func() {
 	var req BasicRequest
 
@@ -72,6 +103,32 @@ var _ = Describe("BasicRequest", func() {
 
 	})
 
+	Context("With Header specified", func() {
+
+		BeforeEach(func() {
+			req.Header = http.Header{"X-Foo": []string{"bar"}, "Cookie": []string{"session=abc; theme=dark"}}
+		})
+
+		Describe("HeaderArgs", func() {
+			It("returns the specified headers", func() {
+				Expect(req.HeaderArgs()).To(Equal(http.Header{"X-Foo": []string{"bar"}, "Cookie": []string{"session=abc; theme=dark"}}))
+			})
+		})
+
+		Describe("CookieArgs", func() {
+			It("returns the cookies parsed from the Cookie header", func() {
+				cookies, err := req.CookieArgs()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cookies).To(HaveLen(2))
+				Expect(cookies[0].Name).To(Equal("session"))
+				Expect(cookies[0].Value).To(Equal("abc"))
+				Expect(cookies[1].Name).To(Equal("theme"))
+				Expect(cookies[1].Value).To(Equal("dark"))
+			})
+		})
+
+	})
+
 	Context("With a valid JSON body", func() {
 		BeforeEach(func() {
 			req.Body = `{"foo": "bar"}`
@@ -111,6 +168,49 @@ var _ = Describe("BasicRequest", func() {
 		})
 	})
 
+	Context("With a multipart body", func() {
+		BeforeEach(func() {
+			var contentType string
+			req.Body, contentType = multipartBody(map[string]string{"one": "two"}, testFile{"avatar", "me.png", "image/png", "PNG"})
+			req.Header = http.Header{"Content-Type": []string{contentType}}
+		})
+
+		Describe("BodyMultipart", func() {
+			It("returns the parsed values and files", func() {
+				form, err := req.BodyMultipart()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(form.Value).To(Equal(map[string][]string{"one": []string{"two"}}))
+				Expect(form.File["avatar"]).To(HaveLen(1))
+				Expect(form.File["avatar"][0].Filename).To(Equal("me.png"))
+				Expect(form.File["avatar"][0].Size).To(Equal(int64(3)))
+			})
+
+			It("parses the body once", func() {
+				form, err := req.BodyMultipart()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(req.BodyMultipart()).To(BeIdenticalTo(form))
+			})
+		})
+
+		Describe("BodyForm", func() {
+			It("returns the text parts", func() {
+				Expect(req.BodyForm()).To(Equal(url.Values{"one": []string{"two"}}))
+			})
+		})
+	})
+
+	Context("With a non-multipart body", func() {
+		BeforeEach(func() {
+			req.Body = "one=two"
+		})
+
+		Describe("BodyMultipart", func() {
+			It("returns nil", func() {
+				Expect(req.BodyMultipart()).To(BeNil())
+			})
+		})
+	})
+
 	Context("With an invalid FORM body", func() {
 		BeforeEach(func() {
 			req.Body = `%ZZ`
@@ -126,3 +226,247 @@ var _ = Describe("BasicRequest", func() {
 	})
 
 })
+
+// countingRequest counts how often each source is parsed, and serves
+// Body as a raw body which can only be read once.
+type countingRequest struct {
+	BasicRequest
+	calls    map[string]int
+	bodyRead bool
+}
+
+func (r *countingRequest) QueryArgs() (url.Values, error) {
+	r.calls["query"]++
+	return r.BasicRequest.QueryArgs()
+}
+
+func (r *countingRequest) HeaderArgs() (http.Header, error) {
+	r.calls["header"]++
+	return r.BasicRequest.HeaderArgs()
+}
+
+func (r *countingRequest) RawBody() (io.Reader, error) {
+	r.calls["body"]++
+	if r.bodyRead {
+		return nil, fmt.Errorf("body already read")
+	}
+	r.bodyRead = true
+	return strings.NewReader(r.Body), nil
+}
+
+var _ = Describe("CachedRequest", func() {
+	var raw *countingRequest
+	var req *CachedRequest
+
+	BeforeEach(func() {
+		raw = &countingRequest{BasicRequest: BasicRequest{Query: "a=b"}, calls: map[string]int{}}
+		req = NewCachedRequest(raw, 0)
+	})
+
+	It("parses each source once", func() {
+		for i := 0; i < 3; i++ {
+			Expect(req.QueryArgs()).To(Equal(url.Values{"a": []string{"b"}}))
+		}
+		Expect(raw.calls["query"]).To(Equal(1))
+	})
+
+	It("doesn't wrap a CachedRequest again", func() {
+		Expect(NewCachedRequest(req, 0)).To(BeIdenticalTo(req))
+	})
+
+	Context("With a raw body", func() {
+		BeforeEach(func() {
+			raw.Body = `{"foo": "bar"}`
+		})
+
+		It("reads the body once and decodes it as JSON and a form", func() {
+			Expect(req.BodyJSON()).To(Equal(map[string]interface{}{"foo": "bar"}))
+			_, err := req.BodyForm()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(req.BodyJSON()).To(Equal(map[string]interface{}{"foo": "bar"}))
+			Expect(raw.calls["body"]).To(Equal(1))
+		})
+
+		It("returns an error if the body is larger than MaxBodySize", func() {
+			req.MaxBodySize = 5
+			_, err := req.BodyJSON()
+			Expect(err).To(MatchError("request body is larger than 5 bytes"))
+			_, err = req.BodyForm()
+			Expect(err).To(MatchError("request body is larger than 5 bytes"))
+		})
+	})
+
+	Context("With a multipart body", func() {
+		BeforeEach(func() {
+			body, contentType := multipartBody(map[string]string{"foo": "bar"})
+			raw.Body = body
+			raw.Header = http.Header{"Content-Type": []string{contentType}}
+		})
+
+		It("leaves the body to the wrapped request", func() {
+			Expect(req.BodyForm()).To(Equal(url.Values{"foo": []string{"bar"}}))
+			Expect(raw.calls["body"]).To(Equal(0))
+		})
+	})
+})
+
+var _ = Describe("HTTPRequest", func() {
+	type args struct {
+		ID      int    `rv:"path.id"`
+		Page    int    `rv:"query.page"`
+		Name    string `rv:"json.name"`
+		Raw     string `rv:"form.name"`
+		Auth    string `rv:"header.Authorization"`
+		Session string `rv:"cookie.session"`
+	}
+
+	var httpReq *http.Request
+
+	BeforeEach(func() {
+		httpReq, _ = http.NewRequest("POST", "/items/42?page=2", strings.NewReader(`{"name": "widget"}`))
+		httpReq.Header.Set("Authorization", "token")
+	})
+
+	It("takes path parameters from the extractor", func() {
+		req := NewHTTPRequest(httpReq, func(r *http.Request) map[string]string {
+			return map[string]string{"id": strings.TrimPrefix(r.URL.Path, "/items/")}
+		})
+		Expect(req.PathArgs()).To(Equal(map[string]string{"id": "42"}))
+	})
+
+	It("has no path parameters without an extractor", func() {
+		Expect(NewHTTPRequest(httpReq, nil).PathArgs()).To(BeNil())
+	})
+
+	It("binds every source, reading the body once for JSON and form fields", func() {
+		httpReq.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+		rh, err := NewRequestHandler(args{})
+		Expect(err).NotTo(HaveOccurred())
+
+		req := NewHTTPRequest(httpReq, func(*http.Request) map[string]string {
+			return map[string]string{"id": "42"}
+		})
+		a := args{}
+		err, fieldErrs := rh.Run(req, &a)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fieldErrs).To(BeEmpty())
+		Expect(a).To(Equal(args{ID: 42, Page: 2, Name: "widget", Raw: "", Auth: "token", Session: "abc"}))
+
+		_, err = req.BodyJSON()
+		Expect(err).To(MatchError("body already read"))
+	})
+
+	It("reads a nil body as empty", func() {
+		httpReq, _ = http.NewRequest("GET", "/items", nil)
+		Expect(NewHTTPRequest(httpReq, nil).BodyJSON()).To(BeNil())
+		Expect(NewHTTPRequest(httpReq, nil).BodyForm()).To(BeNil())
+	})
+
+	Context("With a multipart body", func() {
+		var req *HTTPRequest
+
+		BeforeEach(func() {
+			body, contentType := multipartBody(map[string]string{"name": "widget"}, testFile{"upload", "a.txt", "text/plain", "hello"})
+			httpReq, _ = http.NewRequest("POST", "/items", strings.NewReader(body))
+			httpReq.Header.Set("Content-Type", contentType)
+			req = NewHTTPRequest(httpReq, nil)
+		})
+
+		It("returns the parsed form and can be called again", func() {
+			form, err := req.BodyMultipart()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(form.File["upload"][0].Filename).To(Equal("a.txt"))
+			Expect(req.BodyMultipart()).To(BeIdenticalTo(form))
+		})
+
+		It("keeps the form on the http.Request so net/http removes its temporary files", func() {
+			req.MaxMemory = 1
+			form, err := req.BodyMultipart()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(httpReq.MultipartForm).To(BeIdenticalTo(form))
+		})
+
+		It("returns the text parts as the form", func() {
+			Expect(req.BodyForm()).To(Equal(url.Values{"name": []string{"widget"}}))
+		})
+	})
+})
+
+var _ = Describe("BindMiddleware", func() {
+	type itemArgs struct {
+		ID   int    `rv:"path.id"`
+		Name string `rv:"form.name"`
+	}
+	type itemContext struct {
+		Item itemArgs
+	}
+
+	newRequest := func(r *http.Request) Request {
+		return NewHTTPRequest(r, func(*http.Request) map[string]string {
+			return map[string]string{"id": r.URL.Query().Get("id")}
+		})
+	}
+
+	var res *httptest.ResponseRecorder
+	var bound itemArgs
+	var ok bool
+	var handler http.Handler
+
+	BeforeEach(func() {
+		bound, ok = itemArgs{}, false
+		res = httptest.NewRecorder()
+		handler = BindMiddleware(itemArgs{}, newRequest)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			bound, ok = Bound[itemArgs](r)
+		}))
+	})
+
+	It("binds the struct with the Request from newRequest", func() {
+		handler.ServeHTTP(res, httptest.NewRequest("GET", "/?id=42", nil))
+		Expect(res.Code).To(Equal(http.StatusOK))
+		Expect(ok).To(BeTrue())
+		Expect(bound).To(Equal(itemArgs{ID: 42}))
+	})
+
+	It("writes the field errors instead of calling the handler", func() {
+		handler.ServeHTTP(res, httptest.NewRequest("GET", "/?id=x", nil))
+		Expect(res.Code).To(Equal(http.StatusBadRequest))
+		Expect(ok).To(BeFalse())
+		Expect(res.Body.String()).To(Equal("ID strconv.ParseInt: parsing \"x\": invalid syntax\n"))
+	})
+
+	It("removes the temporary files of forms it parsed", func() {
+		body, contentType := multipartBody(map[string]string{"name": "widget"}, testFile{"upload", "a.txt", "text/plain", "hello"})
+		r := httptest.NewRequest("POST", "/?id=1", strings.NewReader(body))
+		r.Header.Set("Content-Type", contentType)
+
+		var file *multipart.FileHeader
+		BindMiddleware(itemArgs{}, func(r *http.Request) Request {
+			req := newRequest(r).(*HTTPRequest)
+			req.MaxMemory = 1
+			return req
+		})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			bound, ok = Bound[itemArgs](r)
+			file = r.MultipartForm.File["upload"][0]
+			f, err := file.Open()
+			Expect(err).NotTo(HaveOccurred())
+			f.Close()
+		})).ServeHTTP(res, r)
+		Expect(bound).To(Equal(itemArgs{ID: 1, Name: "widget"}))
+		_, err := file.Open()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("binds every struct of a container with BindAllMiddleware", func() {
+		var container itemContext
+		BindAllMiddleware(itemContext{}, newRequest)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			container, ok = Bound[itemContext](r)
+		})).ServeHTTP(res, httptest.NewRequest("GET", "/?id=7", nil))
+		Expect(ok).To(BeTrue())
+		Expect(container).To(Equal(itemContext{Item: itemArgs{ID: 7}}))
+	})
+
+	It("returns nothing for structs which weren't bound", func() {
+		_, ok := Bound[itemArgs](httptest.NewRequest("GET", "/", nil))
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -2,7 +2,10 @@ package rv
 
 import (
 	"fmt"
+	"mime/multipart"
+	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 )
 
@@ -18,6 +21,9 @@ const (
 	QUERY
 	JSON
 	FORM
+	HEADER
+	COOKIE
+	FILE
 )
 
 var sources = []string{
@@ -26,36 +32,68 @@ var sources = []string{
 	"QUERY",
 	"JSON",
 	"FORM",
+	"HEADER",
+	"COOKIE",
+	"FILE",
 }
 
 var sourceMap = map[string]Source{
-	"path":  PATH,
-	"query": QUERY,
-	"json":  JSON,
-	"form":  FORM,
+	"path":   PATH,
+	"query":  QUERY,
+	"json":   JSON,
+	"form":   FORM,
+	"header": HEADER,
+	"cookie": COOKIE,
+	"file":   FILE,
 }
 
 // SourceFieldHandler takes source and field names from the first
 // argument in the rv struct tag, pulls the value from the request and
 // puts it into the field.
+//
+// List is set for slice fields so that sources which can carry several
+// values for one name (such as headers) provide all of them rather than
+// just the first. Map is set for map fields so that query and form
+// values with bracketed keys, like "filter[status]=open", are collected
+// into a map.
+//
+// For list fields, repeated query and form keys such as "id=1&id=2"
+// provide all of their values. For other fields only the first value
+// is used, unless RejectRepeated is set, in which case a repeated key
+// is an error.
+//
+// For the JSON source the field name may be a path into nested objects
+// and arrays, either dot-separated ("json.items.0.id") or as an RFC 6901
+// JSON Pointer ("json./items/0/id"). A path which doesn't exist in the
+// body leaves the field unset, while a path which tries to descend into
+// a value that isn't an object or array is an error.
 type SourceFieldHandler struct {
 	Source Source
 	Field  string
+	List   bool
+	Map    bool
+	// RejectRepeated is set by the "repeated=false" tag option
+	RejectRepeated bool
 }
 
 func NewSourceFieldHandler(args []string) (FieldHandler, error) {
-	source_field := strings.Split(args[0], ".")
-	if len(source_field) != 2 {
+	source_field := strings.SplitN(args[0], ".", 2)
+	if len(source_field) != 2 || source_field[1] == "" {
 		return nil, fmt.Errorf("Expected 'source.field', got '%s'", args[0])
 	}
 	source, field := sourceMap[source_field[0]], source_field[1]
 	if source == UNDEFINED {
 		return nil, fmt.Errorf("Expected one of %v, got '%s'", sources, source_field[0])
 	}
-	return SourceFieldHandler{source, field}, nil
+	if source == JSON {
+		if _, err := jsonPath(field); err != nil {
+			return nil, err
+		}
+	}
+	return SourceFieldHandler{Source: source, Field: field}, nil
 }
 
-func (h SourceFieldHandler) Precidence() int { return 1000 }
+func (h SourceFieldHandler) Precidence() int { return SourceStage }
 
 func (h SourceFieldHandler) Run(r Request, f *Field) {
 	var (
@@ -73,25 +111,196 @@ func (h SourceFieldHandler) Run(r Request, f *Field) {
 	case QUERY:
 		var queryArgs url.Values
 		queryArgs, err = r.QueryArgs()
-		val = queryArgs.Get(h.Field)
-		_, ok = queryArgs[h.Field]
+		val, ok = h.values(queryArgs)
 
 	case JSON:
 		var json map[string]interface{}
 		json, err = r.BodyJSON()
-		val, ok = json[h.Field]
+		if err == nil {
+			val, ok, err = lookupJSON(json, h.Field)
+		}
 
 	case FORM:
 		var form url.Values
 		form, err = r.BodyForm()
-		val = form.Get(h.Field)
-		_, ok = form[h.Field]
+		val, ok = h.values(form)
+
+	case HEADER:
+		var header http.Header
+		header, err = requestHeader(r)
+		val, ok = h.pick(headerValues(header, h.Field))
+
+	case COOKIE:
+		var cookies []*http.Cookie
+		cookies, err = requestCookies(r)
+		var values []string
+		for _, cookie := range cookies {
+			if cookie.Name == h.Field {
+				values = append(values, cookie.Value)
+			}
+		}
+		val, ok = h.pick(values)
+
+	case FILE:
+		var form *multipart.Form
+		form, err = requestMultipart(r)
+		if form != nil && len(form.File[h.Field]) > 0 {
+			files := form.File[h.Field]
+			if h.List || (h.RejectRepeated && len(files) > 1) {
+				val = files
+			} else {
+				val = files[0]
+			}
+			ok = true
+		}
 
 	}
 
 	if err != nil {
-		f.Errors = append(f.Errors, err)
+		f.Errors = append(f.Errors, wrapValidationError("source", nil, nil, err))
+		f.Halt = true
+	} else if repeated := h.repeated(val); repeated != nil {
+		f.Errors = append(f.Errors, repeated)
+		f.Halt = true
 	} else if ok {
 		f.Value = val
+		f.Null = val == nil
+	}
+}
+
+// values looks up the field in query or form values, collecting
+// bracketed keys into a map for map fields.
+func (h SourceFieldHandler) values(vals url.Values) (interface{}, bool) {
+	if !h.Map {
+		return h.pick(vals[h.Field])
+	}
+
+	prefix := h.Field + "["
+	m := map[string]string{}
+	for key := range vals {
+		if strings.HasPrefix(key, prefix) && strings.HasSuffix(key, "]") {
+			m[key[len(prefix):len(key)-1]] = vals.Get(key)
+		}
+	}
+	if len(m) == 0 {
+		return nil, false
+	}
+	return m, true
+}
+
+// pick returns all values for list fields, or when repeated values
+// have to be rejected, and the first value otherwise.
+func (h SourceFieldHandler) pick(values []string) (interface{}, bool) {
+	if len(values) == 0 {
+		return nil, false
+	}
+	if h.List || (h.RejectRepeated && len(values) > 1) {
+		return values, true
+	}
+	return values[0], true
+}
+
+// repeated returns an error if a key was repeated for a field which
+// rejects repeated values.
+func (h SourceFieldHandler) repeated(val interface{}) *ValidationError {
+	if !h.RejectRepeated || h.List {
+		return nil
+	}
+	count := 0
+	switch v := val.(type) {
+	case []string:
+		count = len(v)
+	case []*multipart.FileHeader:
+		count = len(v)
+	}
+	if count < 2 {
+		return nil
+	}
+	return newValidationError("repeated", val, nil, "'%s' given %d times, expected once", h.Field, count)
+}
+
+// headerValues looks up a header without regard to case, even if the
+// header map keys were not canonicalized.
+func headerValues(header http.Header, name string) []string {
+	if values, ok := header[http.CanonicalHeaderKey(name)]; ok {
+		return values
+	}
+	for key, values := range header {
+		if strings.EqualFold(key, name) {
+			return values
+		}
+	}
+	return nil
+}
+
+// jsonPath splits a JSON source field name into its path segments.
+func jsonPath(field string) ([]string, error) {
+	if !strings.HasPrefix(field, "/") {
+		return strings.Split(field, "."), nil
+	}
+
+	// RFC 6901 JSON Pointer
+	path := strings.Split(field[1:], "/")
+	for i, segment := range path {
+		for j := 0; j < len(segment); j++ {
+			if segment[j] == '~' && (j+1 == len(segment) || (segment[j+1] != '0' && segment[j+1] != '1')) {
+				return nil, fmt.Errorf("invalid escape in JSON pointer '%s'", field)
+			}
+		}
+		path[i] = strings.Replace(strings.Replace(segment, "~1", "/", -1), "~0", "~", -1)
+	}
+	return path, nil
+}
+
+// lookupJSON follows the path in field through the parsed JSON body.
+func lookupJSON(body map[string]interface{}, field string) (val interface{}, ok bool, err error) {
+	path, err := jsonPath(field)
+	if err != nil {
+		return nil, false, err
+	}
+
+	val = body
+	for i, segment := range path {
+		switch v := val.(type) {
+		case map[string]interface{}:
+			if val, ok = v[segment]; !ok {
+				return nil, false, nil
+			}
+
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 {
+				return nil, false, fmt.Errorf("expected array index at '%s' in path '%s', got '%s'", strings.Join(path[:i], "."), field, segment)
+			}
+			if index >= len(v) {
+				return nil, false, nil
+			}
+			val = v[index]
+
+		case nil:
+			return nil, false, nil
+
+		default:
+			return nil, false, fmt.Errorf("expected object or array at '%s' in path '%s', got %s", strings.Join(path[:i], "."), field, jsonTypeName(v))
+		}
+	}
+	return val, true, nil
+}
+
+// jsonTypeName gives the JSON name for the type of a decoded JSON value.
+func jsonTypeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	default:
+		return "number"
 	}
 }
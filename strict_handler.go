@@ -0,0 +1,115 @@
+package rv
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// WithStrictTypes makes the RequestHandler check that values from JSON
+// bodies already have the JSON type matching their field, rather than
+// converting them, so "5" is rejected for an int field and 1 for a bool
+// field. Values from other sources are strings and are still parsed. A
+// field can change this with the "strict=true" or "strict=false" tag
+// option.
+func WithStrictTypes(strict bool) Option {
+	return func(h *RequestHandler) error {
+		h.strict = strict
+		return nil
+	}
+}
+
+// StrictTypeHandler rejects JSON values which are not of type JSONType
+// ("boolean", "number", "string", "array" or "object"), and for arrays
+// and objects, elements which are not of type ElemJSONType. An empty
+// type accepts any value. It runs before DefaultHandler so it only
+// checks the value from the request, and halts the field if the value
+// is rejected.
+type StrictTypeHandler struct {
+	JSONType     string
+	ElemJSONType string
+}
+
+func (h StrictTypeHandler) Precidence() int { return 950 }
+
+func (h StrictTypeHandler) Run(r Request, f *Field) {
+	if f.Value == nil {
+		return
+	}
+	if err := checkJSONType(f.Value, h.JSONType); err != nil {
+		f.Errors = append(f.Errors, err)
+		f.Halt = true
+		return
+	}
+
+	switch v := f.Value.(type) {
+	case []interface{}:
+		for i, elem := range v {
+			if err := checkJSONType(elem, h.ElemJSONType); err != nil {
+				addNestedField(f, "["+strconv.Itoa(i)+"]", Field{Value: elem, Errors: []error{err}})
+				f.Halt = true
+			}
+		}
+	case map[string]interface{}:
+		for key, elem := range v {
+			if err := checkJSONType(elem, h.ElemJSONType); err != nil {
+				addNestedField(f, "["+key+"]", Field{Value: elem, Errors: []error{err}})
+				f.Halt = true
+			}
+		}
+	}
+}
+
+func checkJSONType(value interface{}, jsonType string) error {
+	if jsonType == "" || jsonTypeName(value) == jsonType {
+		return nil
+	}
+	return newValidationError("type", value, []string{jsonType}, "expected %s, got %s", jsonType, jsonTypeName(value))
+}
+
+// newStrictTypeHandler returns the StrictTypeHandler for a field of
+// type t, given its "type" tag option, or nil if values of its type
+// aren't checked.
+func newStrictTypeHandler(t reflect.Type, types []string) FieldHandler {
+	if isCustomType(t) {
+		return nil
+	}
+	switch types[0] {
+	case "slice":
+		return StrictTypeHandler{JSONType: "array", ElemJSONType: jsonTypeFor(t.Elem(), types[1])}
+	case "map":
+		return StrictTypeHandler{JSONType: "object", ElemJSONType: jsonTypeFor(t.Elem(), types[2])}
+	}
+	if jsonType := jsonTypeFor(t, types[0]); jsonType != "" {
+		return StrictTypeHandler{JSONType: jsonType}
+	}
+	return nil
+}
+
+// jsonTypeFor gives the JSON type values of type t must have, or "" for
+// types which do their own checking, like nested structs and types
+// with their own conversions.
+func jsonTypeFor(t reflect.Type, name string) string {
+	if isCustomType(derefType(t)) {
+		return ""
+	}
+	switch {
+	case name == "bool":
+		return "boolean"
+	case name == "string", name == "time":
+		return "string"
+	case strings.HasPrefix(name, "int"), strings.HasPrefix(name, "uint"), strings.HasPrefix(name, "float"):
+		return "number"
+	}
+	return ""
+}
+
+// strictOption parses the "strict" tag option.
+func strictOption(args []string) (bool, error) {
+	strict, err := strconv.ParseBool(args[0])
+	if err != nil || len(args) != 1 {
+		return false, fmt.Errorf("invalid value for strict: %#v", args)
+	}
+	return strict, nil
+}
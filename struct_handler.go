@@ -0,0 +1,80 @@
+package rv
+
+import (
+	"mime/multipart"
+	"net/http"
+	"reflect"
+)
+
+// StructHandler fills a nested struct from a JSON object using the
+// "rv" tags on the nested struct's own fields. Errors from the nested
+// fields are kept in Field.Fields, keyed by their nested field names.
+type StructHandler struct {
+	Handler *RequestHandler
+}
+
+func (h StructHandler) Precidence() int { return ConvertStage }
+
+func (h StructHandler) Run(r Request, f *Field) {
+	if f.Value == nil {
+		return
+	}
+
+	obj, ok := f.Value.(map[string]interface{})
+	if !ok {
+		f.Errors = append(f.Errors, newValidationError("type", f.Value, []string{h.Handler.requestType.String()},
+			"expected an object for %v, got %T", h.Handler.requestType, f.Value))
+		f.Halt = true
+		return
+	}
+
+	val := reflect.New(h.Handler.requestType)
+	argErr, fieldErrors := h.Handler.Run(objectRequest{Request: r, object: obj}, val.Interface())
+	if argErr != nil {
+		f.Errors = append(f.Errors, argErr)
+		f.Halt = true
+	} else if len(fieldErrors) > 0 {
+		f.Fields = fieldErrors
+		f.Halt = true
+	} else {
+		f.Value = val.Elem().Interface()
+	}
+}
+
+// objectRequest serves a nested JSON object as the body of a request,
+// leaving the other sources to the parent request.
+type objectRequest struct {
+	Request
+	object map[string]interface{}
+}
+
+func (r objectRequest) BodyJSON() (map[string]interface{}, error) {
+	return r.object, nil
+}
+
+func (r objectRequest) HeaderArgs() (http.Header, error) {
+	return requestHeader(r.Request)
+}
+
+func (r objectRequest) CookieArgs() ([]*http.Cookie, error) {
+	return requestCookies(r.Request)
+}
+
+func (r objectRequest) BodyMultipart() (*multipart.Form, error) {
+	return requestMultipart(r.Request)
+}
+
+// structType returns the struct type a nested struct field binds, or
+// nil if the field type isn't a struct or pointer to a struct.
+func structType(t reflect.Type) reflect.Type {
+	if t == fileHeaderType {
+		return nil
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == timeType {
+		return nil
+	}
+	return t
+}
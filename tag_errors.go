@@ -0,0 +1,202 @@
+package rv
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TagError is a problem with the rv tag on a field, found by
+// NewRequestHandler.
+type TagError struct {
+	// Field is the path of the struct field, e.g. "Address.Zip"
+	Field string
+	Err   error
+}
+
+func (e *TagError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *TagError) Unwrap() error {
+	return e.Err
+}
+
+// TagErrors is every problem NewRequestHandler found in the rv tags of
+// a struct, in field declaration order.
+type TagErrors []*TagError
+
+func (e TagErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// newTagErrors attributes err to the named field, or if err holds the
+// problems with a nested struct, to the nested fields.
+func newTagErrors(field string, err error) TagErrors {
+	switch e := err.(type) {
+	case TagErrors:
+		nested := make(TagErrors, len(e))
+		for i, te := range e {
+			nested[i] = &TagError{Field: field + "." + te.Field, Err: te.Err}
+		}
+		return nested
+	case *TagError:
+		return TagErrors{e}
+	}
+	return TagErrors{{Field: field, Err: err}}
+}
+
+// checkFieldHandlers checks the arguments to a field's handlers make
+// sense for the field's type, given its "type" tag option, so that
+// mistakes show up when the RequestHandler is built rather than on
+// every request.
+func checkFieldHandlers(handlers FieldHandlers, types []string) []error {
+	errs := checkArgs(handlers, types)
+
+	if defaults := defaultHandlers(handlers); len(defaults) > 0 {
+		field := &Field{}
+		defaults.Run(nil, field)
+		for _, err := range field.Errors {
+			errs = append(errs, fmt.Errorf("invalid default %s: %v", formatDefault(defaults), err))
+		}
+		for _, path := range sortedPaths(field.Fields) {
+			for _, err := range field.Fields[path].Errors {
+				errs = append(errs, fmt.Errorf("invalid default %s at %s: %v", formatDefault(defaults), path, err))
+			}
+		}
+	}
+	return errs
+}
+
+// checkArgs checks the range and options handlers against the type
+// they will see, looking into the handlers for slice elements and map
+// keys and values.
+func checkArgs(handlers FieldHandlers, types []string) (errs []error) {
+	for _, handler := range handlers {
+		switch handler.(type) {
+		case CustomTypeHandler, StructHandler:
+			// values are converted to types the range and options
+			// handlers can't check in advance
+			return nil
+		}
+	}
+
+	for _, handler := range handlers {
+		var err error
+		switch h := handler.(type) {
+		case RangeHandler:
+			err = h.check(types[0])
+		case OptionsHandler:
+			err = h.check(types[0])
+		case ListHandler:
+			errs = append(errs, checkArgs(h.SubHandlers, types[1:])...)
+		case MapHandler:
+			errs = append(errs, checkArgs(h.KeyHandlers, types[1:2])...)
+			errs = append(errs, checkArgs(h.ValueHandlers, types[2:])...)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// defaultHandlers picks out the handlers which convert and validate a
+// field's default, so it can be checked without a request, or returns
+// nil if the field has no default.
+func defaultHandlers(handlers FieldHandlers) FieldHandlers {
+	for _, handler := range handlers {
+		if _, ok := handler.(DefaultHandler); ok {
+			return requestlessHandlers(handlers)
+		}
+	}
+	return nil
+}
+
+// requestlessHandlers picks out the built-in handlers which don't need
+// a request.
+func requestlessHandlers(handlers FieldHandlers) (picked FieldHandlers) {
+	for _, handler := range handlers {
+		switch h := handler.(type) {
+		case DefaultHandler, TypeHandler, CustomTypeHandler, RangeHandler, OptionsHandler:
+			picked = append(picked, h)
+		case ListHandler:
+			h.SubHandlers = requestlessHandlers(h.SubHandlers)
+			picked = append(picked, h)
+		}
+	}
+	return picked
+}
+
+func formatDefault(handlers FieldHandlers) string {
+	for _, handler := range handlers {
+		if h, ok := handler.(DefaultHandler); ok {
+			if list, ok := h.Default.([]string); ok {
+				return strconv.Quote(strings.Join(list, ","))
+			}
+			return fmt.Sprintf("%#v", h.Default)
+		}
+	}
+	return ""
+}
+
+// check returns an error if the range can't be used on values of the
+// named type.
+func (h RangeHandler) check(typeName string) error {
+	var err error
+	inOrder := true
+	switch {
+	case strings.HasPrefix(typeName, "int"):
+		var min, max int64
+		min, max, err = h.intRange()
+		inOrder = min <= max
+	case strings.HasPrefix(typeName, "uint"):
+		var min, max uint64
+		min, max, err = h.uintRange()
+		inOrder = min <= max
+	case strings.HasPrefix(typeName, "float"):
+		var min, max float64
+		min, max, err = h.floatRange()
+		inOrder = min <= max
+	case typeName == "string":
+		inOrder = h.Start <= h.End
+	default:
+		return fmt.Errorf("range can't be used on %s fields", typeName)
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid range %s,%s for %s field: %v", h.Start, h.End, typeName, err)
+	}
+	if !inOrder {
+		return fmt.Errorf("invalid range %s,%s: start is after end", h.Start, h.End)
+	}
+	return nil
+}
+
+// check returns an error if any of the options can't be converted to
+// the named type, or would never match a converted value.
+func (h OptionsHandler) check(typeName string) error {
+	if _, ok := acceptedTypes[typeName]; !ok || typeName == "file" {
+		return fmt.Errorf("options can't be used on %s fields", typeName)
+	}
+
+	var bad []string
+	for opt := range h.Options {
+		field := &Field{Value: opt}
+		TypeHandler{Type: typeName}.Run(nil, field)
+		if len(field.Errors) > 0 || fmt.Sprintf("%v", field.Value) != opt {
+			bad = append(bad, opt)
+		}
+	}
+	if len(bad) > 0 {
+		sort.Strings(bad)
+		return fmt.Errorf("options %#v can never match a %s", bad, typeName)
+	}
+	return nil
+}
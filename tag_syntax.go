@@ -0,0 +1,158 @@
+package rv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TagSyntaxError is a malformed rv tag. Offset is the byte offset in the
+// tag where the problem was found.
+type TagSyntaxError struct {
+	Tag    string
+	Offset int
+	Msg    string
+}
+
+func (e *TagSyntaxError) Error() string {
+	return fmt.Sprintf("%s at column %d of %q", e.Msg, e.Offset+1, e.Tag)
+}
+
+// tagOption is a single option from an rv tag.
+type tagOption struct {
+	Key    string
+	Values []string
+	// Offset is the byte offset of the option in the tag.
+	Offset int
+}
+
+// reservedKeys are the tag keys which change how a field is read rather
+// than adding a FieldHandler, so they can't be used as handler names.
+var reservedKeys = map[string]struct{}{
+	"sep":          y,
+	"repeated":     y,
+	"type":         y,
+	"strict":       y,
+	"bases":        y,
+	"leadingzeros": y,
+	"nonfinite":    y,
+}
+
+// parseTag splits an rv tag into its options, in the order they're
+// given. The tag is a space-separated list of options, each a key, an "="
+// and a comma-separated list of values, like
+//
+//	rv:"query.page default=1 range=1,100"
+//
+// An option without a key gives the field's sources. A value may be
+// quoted with single or double quotes to include spaces and commas, and
+// inside quotes a backslash makes the character after it part of the
+// value:
+//
+//	rv:"query.greeting default='hello world' options='a,b','it\'s',c"
+//
+// A value is quoted if it starts with a quote, and the closing quote must
+// then be followed by a space, a comma or the end of the tag. Quotes
+// inside a value and backslashes outside quotes are taken as they are
+// written, so values like ^\d+$ and it's need no quoting.
+func parseTag(tag string) ([]tagOption, error) {
+	p := &tagParser{tag: tag}
+	var opts []tagOption
+	for {
+		p.skipSpaces()
+		if p.done() {
+			return opts, nil
+		}
+		opt, err := p.option()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, opt)
+	}
+}
+
+type tagParser struct {
+	tag string
+	pos int
+}
+
+func (p *tagParser) done() bool { return p.pos >= len(p.tag) }
+
+func (p *tagParser) peek() byte { return p.tag[p.pos] }
+
+func (p *tagParser) atQuote() bool {
+	return !p.done() && (p.peek() == '\'' || p.peek() == '"')
+}
+
+func (p *tagParser) errorf(offset int, format string, args ...interface{}) error {
+	return &TagSyntaxError{Tag: p.tag, Offset: offset, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *tagParser) skipSpaces() {
+	for !p.done() && p.peek() == ' ' {
+		p.pos++
+	}
+}
+
+// option parses "key=values", or just "values" for the sources.
+func (p *tagParser) option() (tagOption, error) {
+	opt := tagOption{Key: "source", Offset: p.pos}
+
+	// A key runs up to the "=", unless the option is a quoted value
+	if !p.atQuote() {
+		if end := strings.IndexAny(p.tag[p.pos:], " ="); end > 0 && p.tag[p.pos+end] == '=' {
+			opt.Key = p.tag[p.pos : p.pos+end]
+			p.pos += end + 1
+		} else if end == 0 && p.peek() == '=' {
+			return opt, p.errorf(p.pos, "missing key before '='")
+		}
+	}
+
+	for {
+		val, err := p.value()
+		if err != nil {
+			return opt, err
+		}
+		opt.Values = append(opt.Values, val)
+		if p.done() || p.peek() == ' ' {
+			return opt, nil
+		}
+		p.pos++ // the comma
+	}
+}
+
+// value parses a single quoted or bare value, stopping before the
+// comma or space after it.
+func (p *tagParser) value() (string, error) {
+	if p.atQuote() {
+		return p.quoted()
+	}
+	start := p.pos
+	for !p.done() && p.peek() != ' ' && p.peek() != ',' {
+		p.pos++
+	}
+	return p.tag[start:p.pos], nil
+}
+
+// quoted parses a quoted value starting at the current position,
+// returning the unescaped value. The closing quote must be followed by
+// a space, a comma or the end of the tag.
+func (p *tagParser) quoted() (string, error) {
+	start, quote := p.pos, p.peek()
+	var b strings.Builder
+	for i := start + 1; i < len(p.tag); i++ {
+		switch c := p.tag[i]; {
+		case c == '\\' && i+1 < len(p.tag):
+			i++
+			b.WriteByte(p.tag[i])
+		case c == quote:
+			if i+1 < len(p.tag) && p.tag[i+1] != ' ' && p.tag[i+1] != ',' {
+				return "", p.errorf(start, "unexpected %q after quoted value", p.tag[i+1])
+			}
+			p.pos = i + 1
+			return b.String(), nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorf(start, "unterminated quoted value")
+}
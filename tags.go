@@ -2,20 +2,72 @@ package rv
 
 import (
 	"fmt"
+	"mime/multipart"
 	"reflect"
-	"strings"
 	"time"
 )
 
-var timeType = reflect.TypeOf(time.Time{})
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	fileHeaderType = reflect.TypeOf(&multipart.FileHeader{})
+)
+
+// typeName gives the name TypeHandler knows a field type by. Pointer
+// fields are named by the type they point to.
+func typeName(t reflect.Type) string {
+	switch t {
+	case timeType:
+		return "time"
+	case fileHeaderType:
+		return "file"
+	}
+	if t.Kind() == reflect.Ptr {
+		return typeName(t.Elem())
+	}
+	return t.Kind().String()
+}
 
-func extractTags(reqStruct interface{}) (map[string]map[string][]string, error) {
+// derefType returns the type a pointer field points to.
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr && t != fileHeaderType {
+		t = t.Elem()
+	}
+	return t
+}
+
+// embeddedTags returns the path of the field, if it is an untagged
+// embedded struct with rv tags itself or in its own embedded structs,
+// or "" if it isn't.
+func embeddedTags(field reflect.StructField, seen map[reflect.Type]bool) string {
+	fieldType := derefType(field.Type)
+	if !field.Anonymous || field.Tag.Get("rv") != "" || fieldType.Kind() != reflect.Struct || seen[fieldType] {
+		return ""
+	}
+	seen[fieldType] = true
+	if hasTags(fieldType) {
+		return field.Name
+	}
+	for i := 0; i < fieldType.NumField(); i++ {
+		if embedded := embeddedTags(fieldType.Field(i), seen); embedded != "" {
+			return field.Name + "." + embedded
+		}
+	}
+	return ""
+}
+
+// extractTags parses the rv tags of the struct's fields into options
+// keyed by field name, using the syntax described on parseTag. Sources
+// given without a name are named by inferSourceName.
+// Malformed tags and duplicated keys are returned as TagErrors along
+// with the options of the other fields.
+func extractTags(reqStruct interface{}, naming NamingStrategy) (map[string]map[string][]string, error) {
 	reqType := reflect.TypeOf(reqStruct)
 	if kind := reqType.Kind(); kind != reflect.Struct {
 		return nil, fmt.Errorf("Expected struct, got %s", kind)
 	}
 
 	tagMap := map[string]map[string][]string{}
+	var errs TagErrors
 	for i := 0; i < reqType.NumField(); i++ {
 		opts := map[string][]string{}
 
@@ -30,26 +82,37 @@ func extractTags(reqStruct interface{}) (map[string]map[string][]string, error)
 			continue
 		}
 
-		if field.Type == timeType {
-			opts["type"] = []string{"time"}
-		} else {
-			kind := field.Type.Kind()
-			opts["type"] = []string{kind.String()}
-		}
+		opts["type"] = []string{typeName(field.Type)}
 
-		if opts["type"][0] == "slice" {
-			opts["type"] = append(opts["type"], field.Type.Elem().Kind().String())
+		fieldType := derefType(field.Type)
+		switch opts["type"][0] {
+		case "slice":
+			opts["type"] = append(opts["type"], typeName(fieldType.Elem()))
+		case "map":
+			opts["type"] = append(opts["type"], typeName(fieldType.Key()), typeName(fieldType.Elem()))
 		}
 
-		for _, opt := range strings.Split(tag, " ") {
-			keyVal := strings.SplitN(opt, "=", 2)
-			if len(keyVal) == 1 {
-				keyVal = []string{"source", keyVal[0]}
+		parsed, err := parseTag(tag)
+		if err != nil {
+			errs = append(errs, &TagError{Field: field.Name, Err: err})
+			continue
+		}
+		seen := map[string]bool{}
+		for _, opt := range parsed {
+			if seen[opt.Key] {
+				errs = append(errs, &TagError{Field: field.Name, Err: fmt.Errorf("duplicate tag key %s at column %d", opt.Key, opt.Offset+1)})
 			}
-			opts[keyVal[0]] = strings.Split(keyVal[1], ",")
+			seen[opt.Key] = true
+			opts[opt.Key] = opt.Values
+		}
+		for i, source := range opts["source"] {
+			opts["source"][i] = inferSourceName(source, field, naming)
 		}
 
 		tagMap[field.Name] = opts
 	}
+	if len(errs) > 0 {
+		return tagMap, errs
+	}
 	return tagMap, nil
 }
@@ -14,7 +14,7 @@ var _ = Describe("tags", func() {
 		It("generates a time entry for a time.Time struct field", func() {
 			tagMap, err := extractTags(struct {
 				T time.Time `rv:"query.t"`
-			}{})
+			}{}, nil)
 			expected := map[string]map[string][]string{
 				"T": map[string][]string{
 					"type":   []string{"time"},
@@ -25,5 +25,136 @@ var _ = Describe("tags", func() {
 			Expect(tagMap).To(Equal(expected))
 		})
 
+		It("uses the pointed-to type for pointer struct fields", func() {
+			tagMap, err := extractTags(struct {
+				I *int       `rv:"query.i"`
+				T *time.Time `rv:"query.t"`
+			}{}, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tagMap["I"]["type"]).To(Equal([]string{"int"}))
+			Expect(tagMap["T"]["type"]).To(Equal([]string{"time"}))
+		})
+
+		It("reports repeated option names", func() {
+			tagMap, err := extractTags(struct {
+				A int `rv:"query.a default=1 default=2"`
+				B int `rv:"query.b required=true"`
+			}{}, nil)
+			Expect(err).To(MatchError("A: duplicate tag key default at column 19"))
+			Expect(tagMap["B"]["required"]).To(Equal([]string{"true"}))
+		})
+
+		It("infers names for sources given without one", func() {
+			tagMap, err := extractTags(struct {
+				ZipCode  string `rv:"json" json:"zip_code,omitempty"`
+				Dotted   string `rv:"json" json:"a.b"`
+				Skipped  string `rv:"json" json:"-"`
+				Page     int    `rv:"query" form:"page"`
+				PerPage  int    `rv:"query" schema:"per_page"`
+				UserID   string `rv:"header"`
+				Explicit string `rv:"query.x" form:"y"`
+			}{}, SnakeCase)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tagMap["ZipCode"]["source"]).To(Equal([]string{"json.zip_code"}))
+			Expect(tagMap["Dotted"]["source"]).To(Equal([]string{"json./a.b"}))
+			Expect(tagMap["Skipped"]["source"]).To(Equal([]string{"json.skipped"}))
+			Expect(tagMap["Page"]["source"]).To(Equal([]string{"query.page"}))
+			Expect(tagMap["PerPage"]["source"]).To(Equal([]string{"query.per_page"}))
+			Expect(tagMap["UserID"]["source"]).To(Equal([]string{"header.user_id"}))
+			Expect(tagMap["Explicit"]["source"]).To(Equal([]string{"query.x"}))
+
+			tagMap, err = extractTags(struct {
+				ZipCode string `rv:"json"`
+			}{}, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tagMap["ZipCode"]["source"]).To(Equal([]string{"json.ZipCode"}))
+		})
+
+	})
+
+	Describe("naming strategies", func() {
+
+		It("splits field names into words", func() {
+			for name, expected := range map[string][]string{
+				"ZipCode":       {"zip_code", "zip-code", "zipCode"},
+				"UserID":        {"user_id", "user-id", "userId"},
+				"HTTPServerID2": {"http_server_id2", "http-server-id2", "httpServerId2"},
+				"ID":            {"id", "id", "id"},
+				"Zip_Code":      {"zip_code", "zip-code", "zipCode"},
+			} {
+				Expect([]string{SnakeCase(name), KebabCase(name), CamelCase(name)}).To(Equal(expected), name)
+			}
+		})
+	})
+
+	Describe("parseTag", func() {
+
+		values := func(tag string) map[string][]string {
+			opts, err := parseTag(tag)
+			Expect(err).ToNot(HaveOccurred())
+			vals := map[string][]string{}
+			for _, opt := range opts {
+				vals[opt.Key] = opt.Values
+			}
+			return vals
+		}
+
+		It("parses unquoted tags as before", func() {
+			Expect(values("query.a,json.a  default=a=b,,it's required=true sep=")).To(Equal(map[string][]string{
+				"source":   []string{"query.a", "json.a"},
+				"default":  []string{"a=b", "", "it's"},
+				"required": []string{"true"},
+				"sep":      []string{""},
+			}))
+		})
+
+		It("keeps spaces and commas in quoted values", func() {
+			Expect(values(`default='hello world' options='a,b',"c d",'',e`)).To(Equal(map[string][]string{
+				"default": []string{"hello world"},
+				"options": []string{"a,b", "c d", "", "e"},
+			}))
+		})
+
+		It("unescapes backslashes in quoted values", func() {
+			Expect(values(`'query.a b' options='it\'s',"\"x\"",'\\'`)).To(Equal(map[string][]string{
+				"source":  []string{"query.a b"},
+				"options": []string{"it's", `"x"`, `\`},
+			}))
+		})
+
+		It("keeps backslashes and quotes inside unquoted values as written", func() {
+			Expect(values(`query.a regex=^\d+$ options=it's,x\,y`)).To(Equal(map[string][]string{
+				"source":  []string{"query.a"},
+				"regex":   []string{`^\d+$`},
+				"options": []string{"it's", `x\`, "y"},
+			}))
+		})
+
+		It("records where each option starts", func() {
+			opts, err := parseTag("query.a  range=1,2")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(opts).To(Equal([]tagOption{
+				{Key: "source", Values: []string{"query.a"}, Offset: 0},
+				{Key: "range", Values: []string{"1", "2"}, Offset: 9},
+			}))
+		})
+
+		It("reports where malformed tags go wrong", func() {
+			_, err := parseTag(`query.a =1`)
+			Expect(err).To(MatchError(`missing key before '=' at column 9 of "query.a =1"`))
+			Expect(err).To(BeAssignableToTypeOf(&TagSyntaxError{}))
+		})
+
+		It("reports unterminated quotes at the opening quote", func() {
+			_, err := parseTag(`query.a default='hello world`)
+			Expect(err).To(MatchError(`unterminated quoted value at column 17 of "query.a default='hello world"`))
+			Expect(err).To(BeAssignableToTypeOf(&TagSyntaxError{}))
+		})
+
+		It("reports text after a closing quote at the opening quote", func() {
+			_, err := parseTag(`query.a options=x,'a,b'c`)
+			Expect(err).To(MatchError(`unexpected 'c' after quoted value at column 19 of "query.a options=x,'a,b'c"`))
+			Expect(err).To(BeAssignableToTypeOf(&TagSyntaxError{}))
+		})
 	})
 })
@@ -1,15 +1,22 @@
 package rv
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
+	"math/big"
+	"mime/multipart"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// TypeHandler converts values to Type. Strings are parsed into numbers
+// as allowed by Numbers.
 type TypeHandler struct {
-	Type string
+	Type    string
+	Numbers NumberFormat
 }
 
 var y = struct{}{}
@@ -20,6 +27,7 @@ var acceptedTypes = map[string]struct{}{
 	"float32": y, "float64": y,
 	"string": y,
 	"time":   y,
+	"file":   y,
 }
 
 func NewTypeHandler(args []string) (FieldHandler, error) {
@@ -27,38 +35,43 @@ func NewTypeHandler(args []string) (FieldHandler, error) {
 	if _, accepted := acceptedTypes[args[0]]; !accepted {
 		return nil, fmt.Errorf("'%s' is not a supported type", args[0])
 	}
-	return TypeHandler{args[0]}, nil
+	return TypeHandler{Type: args[0]}, nil
 }
 
-func (h TypeHandler) Precidence() int { return 800 }
+func (h TypeHandler) Precidence() int { return ConvertStage }
 func (h TypeHandler) Run(r Request, f *Field) {
 	if f.Value == nil {
 		return
 	}
 
 	var err error
+	value := f.Value
 
 	switch h.Type {
 	case "bool":
 		err = toBool(&f.Value)
 	case "int", "int8", "int16", "int32", "int64":
-		err = toInt(&f.Value, h.Type)
+		err = toInt(&f.Value, h.Type, h.Numbers)
 	case "uint", "uint8", "uint16", "uint32", "uint64":
-		err = toUint(&f.Value, h.Type)
+		err = toUint(&f.Value, h.Type, h.Numbers)
 	case "float32":
-		err = toFloat(&f.Value, 32)
+		err = toFloat(&f.Value, 32, h.Numbers)
 	case "float64":
-		err = toFloat(&f.Value, 64)
+		err = toFloat(&f.Value, 64, h.Numbers)
 	case "string":
 		err = toString(&f.Value)
 	case "time":
 		err = toTime(&f.Value)
+	case "file":
+		err = toFile(&f.Value)
 	default:
 		err = fmt.Errorf("don't know how to convert to %s", h.Type)
 	}
 
 	if err != nil {
-		f.Errors = append(f.Errors, err)
+		f.Value = value
+		f.Errors = append(f.Errors, wrapValidationError("type", value, []string{h.Type}, err))
+		f.Halt = true
 	}
 }
 
@@ -100,7 +113,7 @@ const maxInt = int64(int(^uint(0) >> 1))
 const minInt = int64(-maxInt - 1)
 const maxUint = uint64(^uint(0))
 
-func toInt(val *interface{}, intType string) (err error) {
+func toInt(val *interface{}, intType string, nf NumberFormat) (err error) {
 	var i int64
 
 	switch v := (*val).(type) {
@@ -110,7 +123,17 @@ func toInt(val *interface{}, intType string) (err error) {
 		i = ival.Int()
 
 	case string:
-		i, err = strconv.ParseInt(v, 0, 64)
+		i, err = nf.parseInt(v)
+
+	case json.Number, float32, float64:
+		var n *big.Int
+		if n, err = exactInt(v, intType); err == nil {
+			if n.IsInt64() {
+				i = n.Int64()
+			} else {
+				err = fmt.Errorf("int %v can't be represented as %s", v, intType)
+			}
+		}
 
 	default:
 		err = fmt.Errorf("don't know how to convert %T to %s", *val, intType)
@@ -136,7 +159,7 @@ func toInt(val *interface{}, intType string) (err error) {
 	return err
 }
 
-func toUint(val *interface{}, intType string) (err error) {
+func toUint(val *interface{}, intType string, nf NumberFormat) (err error) {
 	var ui uint64
 
 	switch v := (*val).(type) {
@@ -155,7 +178,17 @@ func toUint(val *interface{}, intType string) (err error) {
 		ui = ival.Uint()
 
 	case string:
-		ui, err = strconv.ParseUint(v, 0, 64)
+		ui, err = nf.parseUint(v)
+
+	case json.Number, float32, float64:
+		var n *big.Int
+		if n, err = exactInt(v, intType); err == nil {
+			if n.IsUint64() {
+				ui = n.Uint64()
+			} else {
+				err = fmt.Errorf("int %v can't be represented as %s", v, intType)
+			}
+		}
 
 	default:
 		err = fmt.Errorf("don't know how to convert %T to %s", *val, intType)
@@ -181,7 +214,7 @@ func toUint(val *interface{}, intType string) (err error) {
 	return err
 }
 
-func toFloat(val *interface{}, floatSize int) (err error) {
+func toFloat(val *interface{}, floatSize int, nf NumberFormat) (err error) {
 	var f float64
 
 	switch v := (*val).(type) {
@@ -196,7 +229,7 @@ func toFloat(val *interface{}, floatSize int) (err error) {
 	case float32:
 		if floatSize == 32 {
 			// Leave it alone if it is already float32 so we don't lose any precision
-			return nil
+			return nf.checkFinite(float64(v))
 		}
 		f = float64(v)
 
@@ -204,23 +237,106 @@ func toFloat(val *interface{}, floatSize int) (err error) {
 		f = v
 
 	case string:
-		f, err = strconv.ParseFloat(v, floatSize)
+		f, err = nf.parseFloat(v, floatSize)
+
+	case json.Number:
+		f, err = strconv.ParseFloat(string(v), floatSize)
 
 	default:
 		err = fmt.Errorf("don't know how to convert %T to float%d", *val, floatSize)
 	}
 
+	if err == nil {
+		err = nf.checkFinite(f)
+	}
+
 	if err == nil {
 		switch {
-		case floatSize == 32 && f <= math.MaxFloat32 && f >= -math.MaxFloat32:
+		case floatSize == 32 && (math.IsNaN(f) || math.IsInf(f, 0) || (f <= math.MaxFloat32 && f >= -math.MaxFloat32)):
 			*val = float32(f)
 		case floatSize == 64:
 			*val = f
+		default:
+			err = fmt.Errorf("float %v can't be represented as float%d", f, floatSize)
 		}
 	}
 	return err
 }
 
+// maxFloatInt is the smallest integer a float64 can hold which may have
+// been rounded from another: 1<<53 + 1 rounds to it.
+const maxFloatInt = 1 << 53
+
+// maxIntDigits is the most digits an integer of any supported type has.
+const maxIntDigits = 20
+
+// exactInt converts a JSON number or float to an integer without going
+// through float64, so large IDs aren't rounded. It returns an error if
+// the number has a fractional part, or is a float64 too big to know it
+// wasn't already rounded when it was decoded.
+func exactInt(v interface{}, intType string) (*big.Int, error) {
+	var s string
+	switch v := v.(type) {
+	case json.Number:
+		s = string(v)
+	case float32:
+		s = strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		if math.Abs(v) >= maxFloatInt {
+			return nil, fmt.Errorf("number %v may have been rounded, can't be represented exactly as %s", v, intType)
+		}
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	// check the size first, as big.Rat takes a noticeable time to
+	// expand exponents like 1e1000000
+	digits, err := intDigits(s)
+	if err != nil || digits > maxIntDigits {
+		return nil, fmt.Errorf("number %s can't be represented as %s", s, intType)
+	} else if digits == 0 {
+		return new(big.Int), nil
+	} else if digits < 0 {
+		return nil, fmt.Errorf("number %s has a fractional part, can't be represented as %s", s, intType)
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("number %s can't be represented as %s", s, intType)
+	}
+	if !r.IsInt() {
+		return nil, fmt.Errorf("number %s has a fractional part, can't be represented as %s", s, intType)
+	}
+	return r.Num(), nil
+}
+
+// intDigits gives the number of digits in the integer part of the
+// decimal number s, 0 if s is zero, or -1 if it is between -1 and 1.
+func intDigits(s string) (int, error) {
+	mantissa, exp := strings.TrimLeft(s, "+-"), 0
+	if i := strings.IndexAny(mantissa, "eE"); i >= 0 {
+		var err error
+		if exp, err = strconv.Atoi(mantissa[i+1:]); err != nil {
+			return 0, err
+		}
+		mantissa = mantissa[:i]
+	}
+
+	point := strings.IndexByte(mantissa, '.')
+	if point < 0 {
+		point = len(mantissa)
+	} else {
+		mantissa = mantissa[:point] + mantissa[point+1:]
+	}
+	first := strings.IndexFunc(mantissa, func(r rune) bool { return r != '0' })
+	if first < 0 {
+		return 0, nil
+	}
+	if digits := point - first + exp; digits > 0 {
+		return digits, nil
+	}
+	return -1, nil
+}
+
 func toString(val *interface{}) (err error) {
 	*val = fmt.Sprintf("%v", *val)
 	return err
@@ -244,3 +360,10 @@ func toTime(val *interface{}) (err error) {
 	}
 	return err
 }
+
+func toFile(val *interface{}) (err error) {
+	if _, ok := (*val).(*multipart.FileHeader); !ok {
+		err = fmt.Errorf("expected an uploaded file, got %T", *val)
+	}
+	return err
+}
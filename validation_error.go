@@ -0,0 +1,82 @@
+package rv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationError describes why a value was rejected, in a form API
+// clients can act on. The built-in handlers add ValidationErrors to
+// Field.Errors; RequestHandler.Run fills in Field and Source.
+type ValidationError struct {
+	// Field is the path of the struct field, e.g. "Items[2].Quantity"
+	Field string
+	// Source is the source given in the field's rv tag, e.g. "query.page"
+	Source string
+	// Rule is the name of the check which failed: "source", "repeated",
+	// "type", "range", "options", "required", "maxsize" or
+	// "contenttypes" for the built-in handlers, or "custom" for errors
+	// from other handlers
+	Rule string
+	// Value is the value which was rejected, if there was one
+	Value interface{}
+	// Params are the rule's parameters, e.g. the bounds of a range
+	Params []string
+	// Message describes the error
+	Message string
+	// Err is the underlying error, if there was one
+	Err error
+}
+
+func newValidationError(rule string, value interface{}, params []string, format string, a ...interface{}) *ValidationError {
+	return &ValidationError{Rule: rule, Value: value, Params: params, Message: fmt.Sprintf(format, a...)}
+}
+
+// wrapValidationError returns a copy of err if it is a ValidationError,
+// so it can be annotated without changing an error a handler shares
+// between requests, or wraps it in a ValidationError for the rule.
+func wrapValidationError(rule string, value interface{}, params []string, err error) *ValidationError {
+	if ve, ok := err.(*ValidationError); ok {
+		wrapped := *ve
+		return &wrapped
+	}
+	return &ValidationError{Rule: rule, Value: value, Params: params, Message: err.Error(), Err: err}
+}
+
+// Error returns the message, without the field name, so it reads the
+// same as the errors rv has always returned.
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the underlying error, if any.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors is every ValidationError from a RequestHandler run.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Field + ": " + err.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// newValidationErrors collects the errors in the fields returned by
+// RequestHandler.RunOrdered, keeping their order.
+func newValidationErrors(fieldErrors FieldErrors) ValidationErrors {
+	var errs ValidationErrors
+	for _, fe := range fieldErrors {
+		for _, err := range fe.Errors {
+			ve := wrapValidationError("custom", fe.Value, nil, err)
+			if ve.Field == "" {
+				ve.Field = fe.Name
+			}
+			errs = append(errs, ve)
+		}
+	}
+	return errs
+}